@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"zenith/internal/config"
+	"zenith/internal/daemon"
+	"zenith/internal/notify"
+	"zenith/internal/repository"
+)
+
+func runDaemon(args []string) error {
+	defaultPath, _ := config.Path()
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	configPath := fs.String("config", defaultPath, "path to the config file")
+	dataDir := fs.String("data-dir", "", "directory where tasks and scripts are stored")
+	interval := fs.Duration("interval", time.Minute, "how often to check for due tasks")
+	notifier := fs.String("notifier", "", "dbus, notify-send or command (overrides the config)")
+	command := fs.String("command", "", "shell command for the command notifier")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: zenith daemon [--interval 1m] [--notifier dbus|notify-send|command] [--command <cmd>]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	if *dataDir != "" {
+		cfg.DataDir = *dataDir
+	}
+	if *notifier != "" {
+		cfg.Notifier = *notifier
+	}
+	if *command != "" {
+		cfg.NotifyCommand = *command
+	}
+
+	n, err := notify.New(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := repository.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	d := daemon.New(store, n)
+	d.Interval = *interval
+	fmt.Printf("watching for due tasks every %s, press ctrl+c to stop\n", *interval)
+	err = d.Run(ctx, func(err error) { fmt.Println("Error:", err) })
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
@@ -1,16 +1,64 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"zenith/internal/config"
+	"zenith/internal/repository"
 	"zenith/internal/ui"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
-	if _, err := tea.NewProgram(ui.InitialModel(), tea.WithAltScreen()).Run(); err != nil {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrate(os.Args[2:]); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			return
+		case "daemon":
+			if err := runDaemon(os.Args[2:]); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			return
+		case "query":
+			if err := runQuery(os.Args[2:]); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	defaultPath, _ := config.Path()
+	configPath := flag.String("config", defaultPath, "path to the config file")
+	dataDir := flag.String("data-dir", "", "directory where tasks and scripts are stored")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if *dataDir != "" {
+		cfg.DataDir = *dataDir
+	}
+
+	store, err := repository.Open(cfg)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	if _, err := tea.NewProgram(ui.InitialModel(cfg, store), tea.WithAltScreen()).Run(); err != nil {
 		fmt.Println("Error:", err)
+		store.Close()
 		os.Exit(1)
 	}
 }
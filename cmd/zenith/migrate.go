@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"zenith/internal/repository"
+)
+
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := fs.String("from", "", "source store, e.g. json:~/.zenith")
+	to := fs.String("to", "", "destination store, e.g. sqlite:~/.zenith/zenith.db")
+	dryRun := fs.Bool("dry-run", false, "read the source and report what would be copied")
+	force := fs.Bool("force", false, "overwrite days, scripts, recurring tasks, filters and trash the destination already holds")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: zenith migrate --from <backend>:<location> --to <backend>:<location> [--dry-run] [--force]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *from == "" || *to == "" {
+		fs.Usage()
+		return fmt.Errorf("both --from and --to are required")
+	}
+
+	src, err := repository.OpenSpec(*from, false)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := repository.OpenSpec(*to, true)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	report, err := repository.Migrate(src, dst, repository.MigrateOptions{DryRun: *dryRun, Force: *force})
+	for _, d := range report.Conflicts {
+		fmt.Printf("conflict: %s already has tasks in the destination\n", d.Format("2006-01-02"))
+	}
+	for _, what := range report.DataConflicts {
+		fmt.Printf("conflict: the destination already has its own %s\n", what)
+	}
+	if err != nil {
+		return err
+	}
+
+	verb := "copied"
+	if *dryRun {
+		verb = "would copy"
+	}
+	fmt.Printf("%s %d tasks over %d days, %d scripts, %d recurring tasks, %d filters and %d trashed items\n", verb, report.Tasks, report.Days, report.Scripts, report.Recurrences, report.Filters, report.Trash)
+	fmt.Printf("checksum %s\n", report.Checksum)
+	if !*dryRun {
+		fmt.Println("verified destination against source")
+	}
+	return nil
+}
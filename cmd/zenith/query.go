@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+	"zenith/internal/config"
+	"zenith/internal/model"
+	"zenith/internal/query"
+	"zenith/internal/repository"
+)
+
+func runQuery(args []string) error {
+	defaultPath, _ := config.Path()
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	configPath := fs.String("config", defaultPath, "path to the config file")
+	dataDir := fs.String("data-dir", "", "directory where tasks and scripts are stored")
+	save := fs.String("save", "", "save the query as a filter with this name instead of running it")
+	del := fs.String("delete", "", "delete the saved filter with this name")
+	list := fs.Bool("list", false, "list the saved filters")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: zenith query [--save <name>] <query> | @<name> | --list | --delete <name>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	if *dataDir != "" {
+		cfg.DataDir = *dataDir
+	}
+	store, err := repository.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	filters, err := store.LoadFilters()
+	if err != nil {
+		return err
+	}
+	text := strings.Join(fs.Args(), " ")
+
+	switch {
+	case *list:
+		for _, f := range filters {
+			fmt.Printf("@%-20s %s\n", f.Name, f.Query)
+		}
+		return nil
+
+	case *del != "":
+		i := filterIndex(filters, *del)
+		if i < 0 {
+			return fmt.Errorf("no saved filter %q", *del)
+		}
+		return store.SaveFilters(append(filters[:i], filters[i+1:]...))
+
+	case *save != "":
+		if _, err := query.Parse(text, time.Now()); err != nil {
+			return err
+		}
+		f := model.Filter{Name: *save, Query: text}
+		if i := filterIndex(filters, *save); i >= 0 {
+			filters[i] = f
+		} else {
+			filters = append(filters, f)
+		}
+		return store.SaveFilters(filters)
+	}
+
+	if name, ok := strings.CutPrefix(text, "@"); ok {
+		i := filterIndex(filters, name)
+		if i < 0 {
+			return fmt.Errorf("no saved filter %q", name)
+		}
+		text = filters[i].Query
+	}
+	expr, err := query.Parse(text, time.Now())
+	if err != nil {
+		return err
+	}
+	tasks, err := repository.Select(store, func(dt repository.DatedTask) bool {
+		return expr.Match(dt.Task, dt.Date)
+	})
+	if err != nil {
+		return err
+	}
+	for _, dt := range tasks {
+		fmt.Println(formatTask(dt))
+	}
+	return nil
+}
+
+func filterIndex(filters []model.Filter, name string) int {
+	for i, f := range filters {
+		if strings.EqualFold(f.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatTask renders a task as one line: its day, checkbox, due time,
+// title, priority and tags.
+func formatTask(dt repository.DatedTask) string {
+	t := dt.Task
+	check := "[ ]"
+	if t.Completed {
+		check = "[x]"
+	}
+	line := dt.Date.Format("2006-01-02") + "  " + check + " "
+	if t.Due != "" {
+		line += t.Due + " "
+	}
+	line += t.TitleWithTags()
+	if t.Priority != model.PriorityNone {
+		line += "  (" + t.Priority.String() + ")"
+	}
+	return line
+}
@@ -1,3 +1,139 @@
 package config
 
-const PersistenceDir = "D:\\.zenith"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvHome overrides the data directory when set.
+const EnvHome = "ZENITH_HOME"
+
+// Rollover modes
+const (
+	RolloverOff    = "off"
+	RolloverPrompt = "prompt"
+	RolloverAuto   = "auto"
+)
+
+// Storage backends
+const (
+	StorageJSON   = "json"
+	StorageSQLite = "sqlite"
+)
+
+// Notifiers used by the daemon
+const (
+	NotifierDBus       = "dbus"
+	NotifierNotifySend = "notify-send"
+	NotifierCommand    = "command"
+)
+
+// Config holds the user-tunable settings. Values are layered: built-in
+// defaults, then the config file, then the environment, then CLI flags.
+type Config struct {
+	DataDir string `json:"data_dir"`
+
+	// Backup keeps the previous version of every file as <name>.bak
+	Backup bool `json:"backup"`
+
+	// Storage selects the backend: "json" (one file per day) or "sqlite"
+	Storage string `json:"storage"`
+	// Database is the SQLite file, defaults to zenith.db in DataDir
+	Database string `json:"database"`
+
+	// AutoCompleteParent completes a task once all of its subtasks are done
+	AutoCompleteParent bool `json:"auto_complete_parent"`
+
+	// Rollover decides what happens on startup to unfinished tasks from past
+	// days: "auto" carries them to today, "prompt" asks first, "off" leaves them
+	Rollover string `json:"rollover"`
+	// RolloverCopy leaves the originals in place instead of moving them
+	RolloverCopy bool `json:"rollover_copy"`
+
+	// Notifier picks how the daemon sends reminders: "dbus", "notify-send"
+	// or "command". Empty tries D-Bus, then notify-send
+	Notifier string `json:"notifier"`
+	// NotifyCommand is the shell command run by the "command" notifier
+	NotifyCommand string `json:"notify_command"`
+
+	// TrashRetentionDays is how long deleted tasks and scripts are kept in
+	// the trash before they are purged on startup; 0 keeps them forever
+	TrashRetentionDays int `json:"trash_retention_days"`
+}
+
+// Default returns the built-in configuration.
+func Default() Config {
+	dir := ".zenith"
+	if home, err := os.UserHomeDir(); err == nil {
+		dir = filepath.Join(home, ".zenith")
+	}
+	return Config{DataDir: dir, Backup: true, Storage: StorageJSON, Rollover: RolloverOff, TrashRetentionDays: 30}
+}
+
+// TrashRetention returns TrashRetentionDays as a duration.
+func (c Config) TrashRetention() time.Duration {
+	return time.Duration(c.TrashRetentionDays) * 24 * time.Hour
+}
+
+// DatabasePath returns the SQLite file used by the sqlite backend.
+func (c Config) DatabasePath() string {
+	if c.Database != "" {
+		return c.Database
+	}
+	return filepath.Join(c.DataDir, "zenith.db")
+}
+
+// Path returns the location of the config file,
+// e.g. $XDG_CONFIG_HOME/zenith/config.json on Linux.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "zenith", "config.json"), nil
+}
+
+// Load resolves the configuration from defaults, the config file at path
+// (skipped if empty or missing) and the environment.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// No config file, keep defaults
+		case err != nil:
+			return cfg, err
+		default:
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				return cfg, fmt.Errorf("parse %s: %w", path, err)
+			}
+		}
+	}
+
+	if home := os.Getenv(EnvHome); home != "" {
+		cfg.DataDir = home
+	}
+
+	cfg.DataDir = ExpandHome(cfg.DataDir)
+	cfg.Database = ExpandHome(cfg.Database)
+	return cfg, nil
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory.
+func ExpandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") && !strings.HasPrefix(p, `~\`) {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, p[1:])
+}
@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"zenith/internal/notify"
+	"zenith/internal/repository"
+)
+
+// Daemon polls the store and sends a notification when the due time of an
+// open task arrives. Polling re-reads the store, so changes made by a
+// running TUI are picked up on the next check.
+type Daemon struct {
+	Store    repository.Store
+	Notifier notify.Notifier
+	Interval time.Duration // how often to check, a minute if zero
+
+	last time.Time
+	sent map[reminder]bool // delivered since last, while a failure holds it back
+}
+
+// reminder identifies one due time of one task.
+type reminder struct {
+	id string
+	at int64 // Unix seconds
+}
+
+func New(store repository.Store, notifier notify.Notifier) *Daemon {
+	return &Daemon{Store: store, Notifier: notifier, Interval: time.Minute}
+}
+
+// Run checks for due tasks until ctx is cancelled. Tasks that were already
+// due when Run started are not announced.
+func (d *Daemon) Run(ctx context.Context, errs func(error)) error {
+	interval := d.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	d.last = time.Now()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if err := d.Check(now); err != nil && errs != nil {
+				errs(err)
+			}
+		}
+	}
+}
+
+// Check notifies about every open task whose due time falls after the
+// previous check and no later than now. Each due time is therefore
+// announced once, even across midnight. If a notification fails, the next
+// check covers the same window again but only retries the ones that were
+// not delivered.
+func (d *Daemon) Check(now time.Time) error {
+	prev := d.last
+	if prev.IsZero() || prev.After(now) {
+		prev = now
+	}
+
+	// A check spanning midnight must also look at the previous day.
+	days := []time.Time{repository.Day(now)}
+	if !repository.Day(prev).Equal(days[0]) {
+		days = append([]time.Time{repository.Day(prev)}, days...)
+	}
+
+	var errs []error
+	for _, day := range days {
+		tasks, err := d.Store.LoadTasks(day)
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			at, ok := t.DueAt(day)
+			if !ok || t.Completed || !at.After(prev) || at.After(now) {
+				continue
+			}
+			key := reminder{id: t.ID, at: at.Unix()}
+			if d.sent[key] {
+				continue
+			}
+			n := notify.Notification{
+				Title: t.Title,
+				Body:  fmt.Sprintf("Due at %s", t.Due),
+				Due:   at,
+			}
+			if err := d.Notifier.Notify(n); err != nil {
+				errs = append(errs, fmt.Errorf("notify %q: %w", t.Title, err))
+				continue
+			}
+			if d.sent == nil {
+				d.sent = make(map[reminder]bool)
+			}
+			d.sent[key] = true
+		}
+	}
+	if len(errs) > 0 {
+		// Keep d.last so the next check retries the failed ones.
+		return errors.Join(errs...)
+	}
+	d.last = now
+	clear(d.sent)
+	return nil
+}
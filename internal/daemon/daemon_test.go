@@ -0,0 +1,127 @@
+package daemon
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+	"zenith/internal/model"
+	"zenith/internal/notify"
+	"zenith/internal/repository"
+)
+
+func at(day, hour, min int) time.Time {
+	return time.Date(2026, time.March, day, hour, min, 0, 0, time.Local)
+}
+
+func newDaemon(t *testing.T, n notify.Notifier, days map[time.Time][]model.Task) *Daemon {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	for day, tasks := range days {
+		if err := store.SaveTasks(day, tasks); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return New(store, n)
+}
+
+func titles(sent []notify.Notification) []string {
+	out := make([]string, len(sent))
+	for i, n := range sent {
+		out[i] = n.Title
+	}
+	return out
+}
+
+// check runs Check at now and returns the titles notified by that check.
+func check(t *testing.T, d *Daemon, fake *notify.Fake, now time.Time) []string {
+	t.Helper()
+	before := len(fake.Sent)
+	if err := d.Check(now); err != nil {
+		t.Fatalf("Check(%s): %v", now.Format("15:04"), err)
+	}
+	return titles(fake.Sent[before:])
+}
+
+func TestCheckWindow(t *testing.T) {
+	fake := &notify.Fake{}
+	d := newDaemon(t, fake, map[time.Time][]model.Task{
+		at(10, 0, 0): {
+			{ID: "a", Title: "nine", Due: "09:00"},
+			{ID: "b", Title: "nine-one", Due: "09:01"},
+			{ID: "c", Title: "nine-two", Due: "09:02"},
+			{ID: "d", Title: "done", Due: "09:02", Completed: true},
+			{ID: "e", Title: "undated"},
+		},
+	})
+
+	tests := []struct {
+		now  time.Time
+		want []string
+	}{
+		{at(10, 9, 0), nil}, // the first check only starts the window
+		{at(10, 9, 1), []string{"nine-one"}},
+		{at(10, 9, 1), nil},
+		{at(10, 9, 5), []string{"nine-two"}},
+		{at(10, 9, 3), nil}, // the clock going back does not repeat anything
+	}
+	for _, tt := range tests {
+		if got := check(t, d, fake, tt.now); !slices.Equal(got, tt.want) {
+			t.Errorf("Check(%s) notified %q, want %q", tt.now.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestCheckMidnight(t *testing.T) {
+	fake := &notify.Fake{}
+	d := newDaemon(t, fake, map[time.Time][]model.Task{
+		at(10, 0, 0): {{ID: "a", Title: "late", Due: "23:59"}},
+		at(11, 0, 0): {{ID: "b", Title: "early", Due: "00:00"}, {ID: "c", Title: "later", Due: "00:02"}},
+	})
+
+	check(t, d, fake, at(10, 23, 58))
+	got := check(t, d, fake, at(11, 0, 1))
+	if want := []string{"late", "early"}; !slices.Equal(got, want) {
+		t.Errorf("check across midnight notified %q, want %q", got, want)
+	}
+}
+
+// flaky fails to deliver the notification for one title until it is fixed.
+type flaky struct {
+	notify.Fake
+	broken string
+}
+
+func (f *flaky) Notify(n notify.Notification) error {
+	if n.Title == f.broken {
+		return errors.New("notifier down")
+	}
+	return f.Fake.Notify(n)
+}
+
+func TestCheckRetriesOnlyFailed(t *testing.T) {
+	n := &flaky{broken: "second"}
+	d := newDaemon(t, n, map[time.Time][]model.Task{
+		at(10, 0, 0): {
+			{ID: "a", Title: "first", Due: "09:01"},
+			{ID: "b", Title: "second", Due: "09:01"},
+			{ID: "c", Title: "third", Due: "09:02"},
+		},
+	})
+
+	check(t, d, &n.Fake, at(10, 9, 0))
+	if err := d.Check(at(10, 9, 2)); err == nil {
+		t.Fatal("Check with a failing notification returned nil")
+	}
+	if got, want := titles(n.Sent), []string{"first", "third"}; !slices.Equal(got, want) {
+		t.Fatalf("failing check notified %q, want %q", got, want)
+	}
+
+	n.broken = ""
+	if got, want := check(t, d, &n.Fake, at(10, 9, 3)), []string{"second"}; !slices.Equal(got, want) {
+		t.Errorf("retry notified %q, want %q", got, want)
+	}
+	if got := check(t, d, &n.Fake, at(10, 9, 4)); len(got) != 0 {
+		t.Errorf("check after the retry notified %q again", got)
+	}
+}
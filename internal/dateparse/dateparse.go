@@ -0,0 +1,191 @@
+// Package dateparse reads the dates typed into Zenith's prompts.
+package dateparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"zenith/internal/recur"
+)
+
+var months = map[string]time.Month{
+	"jan": time.January, "january": time.January,
+	"feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"may": time.May,
+	"jun": time.June, "june": time.June,
+	"jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+// Parse returns local midnight of the day described by s, relative to now.
+// It understands:
+//
+//	2026-01-05                      an exact date
+//	today, tomorrow, yesterday      also tod, tom, yday
+//	+3d, -2w, +1m, +1y              offsets in days, weeks, months, years
+//	in 2 weeks, 3 days ago          the same offsets in words
+//	fri, next fri, last fri         the coming Friday, the one of next week, the last one
+//	jan 5, 5 jan, jan 5 2027        a day of the year, the next one to come if no year is given
+func Parse(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	today := day(now)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("no date given")
+	}
+
+	switch s {
+	case "today", "tod", "now":
+		return today, nil
+	case "tomorrow", "tom", "tmr":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday", "yday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	if d, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return d, nil
+	}
+
+	if s[0] == '+' || s[0] == '-' {
+		return offset(today, s[1:], s[0] == '-')
+	}
+	if rest, ok := strings.CutPrefix(s, "in "); ok {
+		return offset(today, rest, false)
+	}
+	if rest, ok := strings.CutSuffix(s, " ago"); ok {
+		return offset(today, rest, true)
+	}
+
+	if d, ok, err := weekday(today, s); ok {
+		return d, err
+	}
+	if d, ok, err := monthDay(today, s); ok {
+		return d, err
+	}
+	return time.Time{}, fmt.Errorf("unknown date %q", s)
+}
+
+// offset reads "3d", "3 days" or "a week" and moves today by that much.
+func offset(today time.Time, s string, back bool) (time.Time, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	n := 1
+	num, unit := s[:i], s[i:]
+	if num != "" {
+		var err error
+		if n, err = strconv.Atoi(num); err != nil {
+			return time.Time{}, fmt.Errorf("invalid number %q: %w", num, err)
+		}
+	} else if u, ok := strings.CutPrefix(unit, "a"); ok && u != "" {
+		unit = u // "a week"
+	} else {
+		return time.Time{}, fmt.Errorf("expected a number in %q", s)
+	}
+	if back {
+		n = -n
+	}
+
+	switch strings.TrimSuffix(unit, "s") {
+	case "d", "day":
+		return today.AddDate(0, 0, n), nil
+	case "w", "wk", "week":
+		return today.AddDate(0, 0, 7*n), nil
+	case "m", "mo", "month":
+		return addMonths(today, n), nil
+	case "y", "yr", "year":
+		return addMonths(today, 12*n), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown unit %q, use d, w, m or y", unit)
+}
+
+// addMonths moves d by n months, keeping the day of the month where the
+// target month has it and using its last day otherwise, so a month after
+// Jan 31 is Feb 28 rather than Mar 3.
+func addMonths(d time.Time, n int) time.Time {
+	y, m, dom := d.Date()
+	last := time.Date(y, m+time.Month(n)+1, 0, 0, 0, 0, 0, time.Local).Day()
+	return time.Date(y, m+time.Month(n), min(dom, last), 0, 0, 0, 0, time.Local)
+}
+
+// weekday reads "fri", "this fri", "next fri" and "last fri". A bare or
+// "this" weekday is the next one after today; "next" is that weekday in the
+// following week, counted from Monday.
+func weekday(today time.Time, s string) (time.Time, bool, error) {
+	which, name, found := strings.Cut(s, " ")
+	if !found {
+		which, name = "", s
+	}
+	wd, ok := recur.ParseWeekday(name)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	switch which {
+	case "", "this", "on":
+		ahead := (int(wd) - int(today.Weekday()) + 7) % 7
+		if ahead == 0 {
+			ahead = 7
+		}
+		return today.AddDate(0, 0, ahead), true, nil
+	case "next":
+		monday := today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7)
+		return monday.AddDate(0, 0, (int(wd)+6)%7), true, nil
+	case "last":
+		back := (int(today.Weekday()) - int(wd) + 7) % 7
+		if back == 0 {
+			back = 7
+		}
+		return today.AddDate(0, 0, -back), true, nil
+	}
+	return time.Time{}, true, fmt.Errorf("unknown date %q", s)
+}
+
+// monthDay reads "jan 5", "5 jan" or "january 5th", with an optional year.
+func monthDay(today time.Time, s string) (time.Time, bool, error) {
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	if len(fields) < 2 || len(fields) > 3 {
+		return time.Time{}, false, nil
+	}
+	m, ok := months[fields[0]]
+	dayField := fields[1]
+	if !ok {
+		if m, ok = months[fields[1]]; !ok {
+			return time.Time{}, false, nil
+		}
+		dayField = fields[0]
+	}
+
+	n, err := strconv.Atoi(strings.TrimRight(dayField, "stndrh")) // 1st, 2nd, 3rd, 5th
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("invalid day %q", dayField)
+	}
+	year := today.Year()
+	if len(fields) == 3 {
+		if year, err = strconv.Atoi(fields[2]); err != nil {
+			return time.Time{}, true, fmt.Errorf("invalid year %q", fields[2])
+		}
+	}
+	d := time.Date(year, m, n, 0, 0, 0, 0, time.Local)
+	if d.Month() != m || n < 1 {
+		return time.Time{}, true, fmt.Errorf("%s has no day %d", m, n)
+	}
+	if len(fields) == 2 && d.Before(today) {
+		d = time.Date(year+1, m, n, 0, 0, 0, 0, time.Local)
+	}
+	return d, true, nil
+}
+
+// day truncates t to local midnight.
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
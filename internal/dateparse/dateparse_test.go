@@ -0,0 +1,106 @@
+package dateparse
+
+import (
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestParse(t *testing.T) {
+	wed := time.Date(2026, time.March, 4, 15, 30, 0, 0, time.Local)
+	sun := time.Date(2026, time.March, 8, 9, 0, 0, 0, time.Local)
+	jan31 := time.Date(2026, time.January, 31, 12, 0, 0, 0, time.Local)
+	leap := time.Date(2028, time.February, 29, 12, 0, 0, 0, time.Local)
+	dec30 := time.Date(2026, time.December, 30, 23, 59, 0, 0, time.Local)
+
+	tests := []struct {
+		in   string
+		now  time.Time
+		want time.Time
+	}{
+		{"2026-02-03", wed, date(2026, time.February, 3)},
+		{"today", wed, date(2026, time.March, 4)},
+		{"  Tomorrow ", wed, date(2026, time.March, 5)},
+		{"yday", wed, date(2026, time.March, 3)},
+
+		{"+3d", wed, date(2026, time.March, 7)},
+		{"-2w", wed, date(2026, time.February, 18)},
+		{"in 2 weeks", wed, date(2026, time.March, 18)},
+		{"3 days ago", wed, date(2026, time.March, 1)},
+		{"a week ago", wed, date(2026, time.February, 25)},
+		{"in a month", wed, date(2026, time.April, 4)},
+
+		// Months and years keep the day where they can and clamp otherwise.
+		{"+1m", jan31, date(2026, time.February, 28)},
+		{"+2m", jan31, date(2026, time.March, 31)},
+		{"+3mo", jan31, date(2026, time.April, 30)},
+		{"-2m", jan31, date(2025, time.November, 30)},
+		{"+1y", leap, date(2029, time.February, 28)},
+		{"-4y", leap, date(2024, time.February, 29)},
+
+		// A bare weekday is the next one after today, never today.
+		{"fri", wed, date(2026, time.March, 6)},
+		{"wed", wed, date(2026, time.March, 11)},
+		{"this tue", wed, date(2026, time.March, 10)},
+		{"mon", sun, date(2026, time.March, 9)},
+		// "next" is that day in the week starting next Monday.
+		{"next fri", wed, date(2026, time.March, 13)},
+		{"next wed", wed, date(2026, time.March, 11)},
+		{"next mon", wed, date(2026, time.March, 9)},
+		{"next sun", wed, date(2026, time.March, 15)},
+		{"next mon", sun, date(2026, time.March, 9)},
+		{"next sun", sun, date(2026, time.March, 15)},
+		// "last" is the latest one before today.
+		{"last wed", wed, date(2026, time.February, 25)},
+		{"last tue", wed, date(2026, time.March, 3)},
+		{"last sun", sun, date(2026, time.March, 1)},
+
+		{"jan 5", wed, date(2027, time.January, 5)},
+		{"march 4", wed, date(2026, time.March, 4)},
+		{"5th Jan", dec30, date(2027, time.January, 5)},
+		{"dec 30", dec30, date(2026, time.December, 30)},
+		{"dec 29", dec30, date(2027, time.December, 29)},
+		{"jan 5, 2026", dec30, date(2026, time.January, 5)},
+		{"feb 29 2028", wed, date(2028, time.February, 29)},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in, tt.now)
+		if err != nil {
+			t.Errorf("Parse(%q, %s): %v", tt.in, tt.now.Format("Mon 2006-01-02"), err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Parse(%q, %s) = %s, want %s", tt.in, tt.now.Format("Mon 2006-01-02"),
+				got.Format("Mon 2006-01-02"), tt.want.Format("Mon 2006-01-02"))
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	now := time.Date(2026, time.March, 4, 15, 30, 0, 0, time.Local)
+	for _, in := range []string{
+		"",
+		"   ",
+		"someday",
+		"2026-13-01",
+		"+3x",
+		"+d",
+		"in",
+		"+99999999999999999999d",
+		"in 99999999999999999999 weeks",
+		"in lots of days",
+		"someday fri",
+		"feb 30",
+		"feb 29 2027",
+		"jan 0",
+		"jan x",
+		"jan 5 20x6",
+	} {
+		if got, err := Parse(in, now); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", in, got.Format("2006-01-02"))
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package fuzzy implements fzf-style subsequence matching: every character
+// of the pattern must appear in the text, in order, and matches that are
+// consecutive or start words score higher.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scoring, loosely following fzf's defaults.
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8 // match at the start of a word
+	bonusCamel       = 7 // match at a lower-to-upper case change
+	bonusConsecutive = 4 // match right after the previous one
+	bonusFirstChar   = 2 // multiplier for the bonus of the pattern's first character
+	penaltyGapStart  = 3
+	penaltyGapExtend = 1
+)
+
+// Result describes a successful match.
+type Result struct {
+	Score     int
+	Positions []int // rune indexes into the text, ascending
+}
+
+// Match reports whether pattern is a case-insensitive subsequence of text
+// and how well it matches. An empty pattern matches everything with score 0.
+func Match(pattern, text string) (Result, bool) {
+	pat := []rune(strings.ToLower(pattern))
+	if len(pat) == 0 {
+		return Result{}, true
+	}
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		lower = runes // lowering changed the length; fall back to exact runes
+	}
+
+	// Find where the leftmost match ends, then walk back from there to the
+	// latest start, which gives the tightest window, as fzf's v1 does.
+	end, p := -1, 0
+	for i := 0; i < len(lower) && p < len(pat); i++ {
+		if lower[i] == pat[p] {
+			p++
+			if p == len(pat) {
+				end = i
+			}
+		}
+	}
+	if end < 0 {
+		return Result{}, false
+	}
+	start := end
+	for i, p := end, len(pat)-1; i >= 0; i-- {
+		if lower[i] == pat[p] {
+			if p == 0 {
+				start = i
+				break
+			}
+			p--
+		}
+	}
+
+	// Score the window, matching each character as early as possible.
+	res := Result{Positions: make([]int, 0, len(pat))}
+	prev, inGap := -1, false
+	p = 0
+	for i := start; i <= end && p < len(pat); i++ {
+		if lower[i] != pat[p] {
+			if prev >= 0 {
+				if inGap {
+					res.Score -= penaltyGapExtend
+				} else {
+					res.Score -= penaltyGapStart
+				}
+				inGap = true
+			}
+			continue
+		}
+		bonus := charBonus(runes, i)
+		if p == 0 {
+			bonus *= bonusFirstChar
+		}
+		if prev == i-1 && prev >= 0 {
+			bonus = max(bonus, bonusConsecutive)
+		}
+		res.Score += scoreMatch + bonus
+		res.Positions = append(res.Positions, i)
+		prev, inGap = i, false
+		p++
+	}
+	return res, true
+}
+
+// charBonus rewards matching the character at i for where it sits in a word.
+func charBonus(runes []rune, i int) int {
+	if i == 0 {
+		return bonusBoundary
+	}
+	before, cur := runes[i-1], runes[i]
+	switch {
+	case !unicode.IsLetter(before) && !unicode.IsDigit(before):
+		return bonusBoundary
+	case unicode.IsLower(before) && unicode.IsUpper(cur):
+		return bonusCamel
+	}
+	return 0
+}
+
+// Ranked is one of the texts matched by Filter.
+type Ranked struct {
+	Index int // position in the texts passed to Filter
+	Result
+}
+
+// Filter matches pattern against every text and returns the matches, best
+// first; equal scores keep their original order.
+func Filter(pattern string, texts []string) []Ranked {
+	var out []Ranked
+	for i, text := range texts {
+		if r, ok := Match(pattern, text); ok {
+			out = append(out, Ranked{Index: i, Result: r})
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].Score > out[b].Score })
+	return out
+}
@@ -0,0 +1,84 @@
+package fuzzy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		ok            bool
+		positions     []int
+	}{
+		{"", "anything", true, nil},
+		{"abc", "abc", true, []int{0, 1, 2}},
+		{"wrp", "write report", true, []int{0, 1, 8}},
+		{"WR", "write report", true, []int{0, 1}},
+		{"wr", "WRITE", true, []int{0, 1}},
+		{"cba", "abc", false, nil}, // out of order
+		{"abcd", "abc", false, nil},
+		{"rep", "repeat report", true, []int{0, 1, 2}},
+		// The tightest window wins over the leftmost start.
+		{"ab", "a xab", true, []int{3, 4}},
+		// İ lowercases to two runes, so positions must still index the text.
+		{"bul", "İstanbul", true, []int{5, 6, 7}},
+		{"straße", "STRASSE", false, nil},
+	}
+	for _, tt := range tests {
+		res, ok := Match(tt.pattern, tt.text)
+		if ok != tt.ok {
+			t.Errorf("Match(%q, %q) ok = %v, want %v", tt.pattern, tt.text, ok, tt.ok)
+			continue
+		}
+		if ok && !slices.Equal(res.Positions, tt.positions) {
+			t.Errorf("Match(%q, %q) positions = %v, want %v", tt.pattern, tt.text, res.Positions, tt.positions)
+		}
+	}
+}
+
+func TestMatchBonuses(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		better, worse string
+	}{
+		{"word boundary", "r", "a report", "art"},
+		{"word boundary after punctuation", "b", "a-b", "ab"},
+		{"camel case", "b", "aB", "ab"},
+		{"consecutive", "ab", "xabx", "xaxb"},
+		{"first character at a boundary", "re", "report", "fire"},
+		{"shorter gap", "ac", "abc", "abbbbc"},
+	}
+	for _, tt := range tests {
+		b, okB := Match(tt.pattern, tt.better)
+		w, okW := Match(tt.pattern, tt.worse)
+		if !okB || !okW {
+			t.Errorf("%s: %q does not match both %q and %q", tt.name, tt.pattern, tt.better, tt.worse)
+			continue
+		}
+		if b.Score <= w.Score {
+			t.Errorf("%s: %q scores %d in %q, not more than %d in %q", tt.name, tt.pattern, b.Score, tt.better, w.Score, tt.worse)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	texts := []string{"xaxbxc", "nothing", "abc", "ABC", "a_b_c"}
+	var got []int
+	for _, r := range Filter("abc", texts) {
+		got = append(got, r.Index)
+		if want, _ := Match("abc", texts[r.Index]); r.Score != want.Score {
+			t.Errorf("Filter scored %q %d, Match %d", texts[r.Index], r.Score, want.Score)
+		}
+	}
+	// a_b_c starts every match at a word, abc and ABC tie and keep their
+	// order, and the scattered match comes last.
+	if want := []int{4, 2, 3, 0}; !slices.Equal(got, want) {
+		t.Errorf("Filter order = %v, want %v", got, want)
+	}
+
+	if all := Filter("", texts); len(all) != len(texts) {
+		t.Errorf("Filter with an empty pattern kept %d of %d texts", len(all), len(texts))
+	}
+}
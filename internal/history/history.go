@@ -0,0 +1,79 @@
+// Package history keeps the undo and redo stacks of a session.
+package history
+
+import "errors"
+
+// ErrConflict is returned, possibly wrapped, by a command that cannot be
+// undone or redone because the data it would overwrite changed since. The
+// commands recorded before it are then out of date too, so History drops
+// them all.
+var ErrConflict = errors.New("changed since")
+
+// Command is a change that can be reverted and applied again.
+type Command interface {
+	Undo() error
+	Redo() error
+	// String names the change for status messages, e.g. `delete "Buy milk"`.
+	String() string
+}
+
+// History holds the commands done in a session, newest last. Pushing a new
+// command clears the redo stack.
+type History struct {
+	Limit int // how many commands to keep, 0 for no limit
+
+	undo, redo []Command
+}
+
+// New returns an empty History keeping up to limit commands.
+func New(limit int) *History {
+	return &History{Limit: limit}
+}
+
+// Push records a command that has just been done.
+func (h *History) Push(c Command) {
+	h.undo = append(h.undo, c)
+	if h.Limit > 0 && len(h.undo) > h.Limit {
+		h.undo = h.undo[len(h.undo)-h.Limit:]
+	}
+	h.redo = nil
+}
+
+// Undo reverts the newest command and returns it, or nil when there is
+// nothing to undo. A command that fails to undo stays on the stack, unless
+// it failed with ErrConflict, which clears the history.
+func (h *History) Undo() (Command, error) {
+	if len(h.undo) == 0 {
+		return nil, nil
+	}
+	c := h.undo[len(h.undo)-1]
+	if err := c.Undo(); err != nil {
+		h.dropOnConflict(err)
+		return c, err
+	}
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, c)
+	return c, nil
+}
+
+// Redo applies the last undone command again and returns it, or nil when
+// there is nothing to redo. Failures are handled as in Undo.
+func (h *History) Redo() (Command, error) {
+	if len(h.redo) == 0 {
+		return nil, nil
+	}
+	c := h.redo[len(h.redo)-1]
+	if err := c.Redo(); err != nil {
+		h.dropOnConflict(err)
+		return c, err
+	}
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, c)
+	return c, nil
+}
+
+func (h *History) dropOnConflict(err error) {
+	if errors.Is(err, ErrConflict) {
+		h.undo, h.redo = nil, nil
+	}
+}
@@ -0,0 +1,127 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// step is a command that records what was done to a shared log and fails
+// with fail, if set.
+type step struct {
+	name string
+	log  *[]string
+	fail *error
+}
+
+func (s step) Undo() error {
+	if *s.fail != nil {
+		return *s.fail
+	}
+	*s.log = append(*s.log, "undo "+s.name)
+	return nil
+}
+
+func (s step) Redo() error {
+	if *s.fail != nil {
+		return *s.fail
+	}
+	*s.log = append(*s.log, "redo "+s.name)
+	return nil
+}
+
+func (s step) String() string { return s.name }
+
+type recorder struct {
+	log  []string
+	fail error
+}
+
+func (r *recorder) step(name string) Command { return step{name: name, log: &r.log, fail: &r.fail} }
+
+func TestHistory(t *testing.T) {
+	r := &recorder{}
+	h := New(0)
+	h.Push(r.step("a"))
+	h.Push(r.step("b"))
+
+	for _, want := range []string{"b", "a"} {
+		if c, err := h.Undo(); err != nil || c == nil || c.String() != want {
+			t.Fatalf("Undo = %v, %v; want %s", c, err, want)
+		}
+	}
+	if c, _ := h.Undo(); c != nil {
+		t.Errorf("Undo with nothing left = %v, want nothing", c)
+	}
+	if c, _ := h.Redo(); c == nil || c.String() != "a" {
+		t.Errorf("Redo = %v, want a", c)
+	}
+	if want := []string{"undo b", "undo a", "redo a"}; !slices.Equal(r.log, want) {
+		t.Errorf("log = %q, want %q", r.log, want)
+	}
+
+	// A new change drops what was undone.
+	h.Push(r.step("c"))
+	if c, _ := h.Redo(); c != nil {
+		t.Errorf("Redo after Push = %v, want nothing", c)
+	}
+	if c, _ := h.Undo(); c == nil || c.String() != "c" {
+		t.Errorf("Undo after Push = %v, want c", c)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	r := &recorder{}
+	h := New(2)
+	for _, name := range []string{"a", "b", "c"} {
+		h.Push(r.step(name))
+	}
+	var undone []string
+	for {
+		c, err := h.Undo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c == nil {
+			break
+		}
+		undone = append(undone, c.String())
+	}
+	if want := []string{"c", "b"}; !slices.Equal(undone, want) {
+		t.Errorf("undid %q, want the newest two %q", undone, want)
+	}
+}
+
+func TestHistoryFailure(t *testing.T) {
+	r := &recorder{}
+	h := New(0)
+	h.Push(r.step("a"))
+	h.Push(r.step("b"))
+
+	// An ordinary failure leaves the command to be tried again.
+	r.fail = errors.New("disk full")
+	if c, err := h.Undo(); err != r.fail || c.String() != "b" {
+		t.Errorf("Undo = %v, %v; want b, disk full", c, err)
+	}
+	r.fail = nil
+	if c, err := h.Undo(); err != nil || c.String() != "b" {
+		t.Errorf("Undo after the failure = %v, %v; want b", c, err)
+	}
+
+	// A conflict makes the rest of the history stale, so it is dropped.
+	r.fail = fmt.Errorf("tasks %w", ErrConflict)
+	if _, err := h.Redo(); !errors.Is(err, ErrConflict) {
+		t.Errorf("Redo = %v, want ErrConflict", err)
+	}
+	r.fail = nil
+	if c, _ := h.Undo(); c != nil {
+		t.Errorf("Undo after a conflict = %v, want nothing", c)
+	}
+	if c, _ := h.Redo(); c != nil {
+		t.Errorf("Redo after a conflict = %v, want nothing", c)
+	}
+	if want := []string{"undo b"}; !slices.Equal(r.log, want) {
+		t.Errorf("log = %q, want %q", r.log, want)
+	}
+}
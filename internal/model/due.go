@@ -0,0 +1,60 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const clockLayout = "15:04"
+
+// ParseClock reads a time of day such as "9", "9:30", "14:05", "9am" or
+// "7:45pm" and returns it as HH:MM.
+func ParseClock(s string) (string, error) {
+	s = strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	suffix := ""
+	if strings.HasSuffix(s, "am") || strings.HasSuffix(s, "pm") {
+		suffix = s[len(s)-2:]
+		s = s[:len(s)-2]
+	}
+	hs, ms, _ := strings.Cut(s, ":")
+	h, err := strconv.Atoi(hs)
+	if err != nil {
+		return "", fmt.Errorf("invalid time %q", s+suffix)
+	}
+	m := 0
+	if ms != "" {
+		if m, err = strconv.Atoi(ms); err != nil || len(ms) != 2 {
+			return "", fmt.Errorf("invalid time %q", s+suffix)
+		}
+	}
+	switch suffix {
+	case "am", "pm":
+		if h < 1 || h > 12 {
+			return "", fmt.Errorf("invalid time %q", s+suffix)
+		}
+		h %= 12
+		if suffix == "pm" {
+			h += 12
+		}
+	}
+	if h < 0 || h > 23 || m < 0 || m > 59 {
+		return "", fmt.Errorf("invalid time %q", s+suffix)
+	}
+	return fmt.Sprintf("%02d:%02d", h, m), nil
+}
+
+// DueAt returns when the task is due if it is scheduled on day, and false
+// if it has no due time.
+func (t Task) DueAt(day time.Time) (time.Time, bool) {
+	if t.Due == "" {
+		return time.Time{}, false
+	}
+	clock, err := time.Parse(clockLayout, t.Due)
+	if err != nil {
+		return time.Time{}, false
+	}
+	y, m, d := day.Date()
+	return time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, day.Location()), true
+}
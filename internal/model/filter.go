@@ -0,0 +1,7 @@
+package model
+
+// Filter is a saved query, listed in the TUI as the tasks it matches.
+type Filter struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+func (p Priority) String() string {
+	if p < PriorityNone || p > PriorityUrgent {
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// Raise returns the next higher priority, stopping at urgent.
+func (p Priority) Raise() Priority {
+	if p >= PriorityUrgent {
+		return PriorityUrgent
+	}
+	return p + 1
+}
+
+// Lower returns the next lower priority, stopping at none.
+func (p Priority) Lower() Priority {
+	if p <= PriorityNone {
+		return PriorityNone
+	}
+	return p - 1
+}
+
+// ParsePriority accepts a priority name such as "high" or "urgent".
+func ParsePriority(s string) (Priority, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i, name := range priorityNames {
+		if s == name {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("unknown priority %q", s)
+}
+
+// Priorities are stored by name so the files stay readable.
+
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Priority) UnmarshalText(text []byte) error {
+	v, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
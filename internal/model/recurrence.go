@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Recurrence defines a task that repeats. Instances are created on the days
+// the rule matches and are stored like any other task once saved, so
+// completing one instance does not affect the others.
+type Recurrence struct {
+	ID    string `json:"id"`
+	Rule  string `json:"rule"`  // see recur.Parse
+	Start string `json:"start"` // YYYY-MM-DD of the first occurrence
+
+	Title    string   `json:"title"`
+	Priority Priority `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Notes    string   `json:"notes,omitempty"`
+
+	// Exceptions lists days (YYYY-MM-DD) whose instance was deleted
+	Exceptions []string `json:"exceptions,omitempty"`
+}
+
+// Skips reports whether the instance on day (YYYY-MM-DD) was deleted.
+func (r Recurrence) Skips(day string) bool {
+	for _, d := range r.Exceptions {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Instance returns the task for the occurrence on day d.
+func (r Recurrence) Instance(id string, d time.Time) Task {
+	y, m, day := d.Date()
+	return Task{
+		ID:           id,
+		Title:        r.Title,
+		Priority:     r.Priority,
+		Tags:         append([]string(nil), r.Tags...),
+		Notes:        r.Notes,
+		CreatedAt:    time.Date(y, m, day, 0, 0, 0, 0, d.Location()),
+		RecurrenceID: r.ID,
+	}
+}
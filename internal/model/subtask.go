@@ -0,0 +1,33 @@
+package model
+
+// Subtask is a checklist item nested under a task.
+type Subtask struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Completed bool   `json:"completed"`
+}
+
+// Progress returns how many subtasks are done out of how many.
+func (t Task) Progress() (done, total int) {
+	for _, s := range t.Subtasks {
+		if s.Completed {
+			done++
+		}
+	}
+	return done, len(t.Subtasks)
+}
+
+// ToggleSubtask flips subtask i. With autoComplete set, the task itself is
+// completed once every subtask is done, and reopened when one is unchecked.
+func (t *Task) ToggleSubtask(i int, autoComplete bool) {
+	t.Subtasks[i].Completed = !t.Subtasks[i].Completed
+	if !autoComplete {
+		return
+	}
+	done, total := t.Progress()
+	if done == total && !t.Completed {
+		t.SetCompleted(true)
+	} else if done < total && t.Completed {
+		t.SetCompleted(false)
+	}
+}
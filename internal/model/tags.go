@@ -0,0 +1,65 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// ParseTags splits #tag tokens out of a title, e.g. "Fix build #work #ci"
+// returns "Fix build" and ["ci", "work"]. Tags are lowercased and deduplicated.
+func ParseTags(title string) (string, []string) {
+	var words []string
+	var tags []string
+	for _, w := range strings.Fields(title) {
+		if len(w) > 1 && w[0] == '#' {
+			tags = append(tags, w[1:])
+			continue
+		}
+		words = append(words, w)
+	}
+	return strings.Join(words, " "), NormalizeTags(tags)
+}
+
+// NormalizeTags lowercases, strips leading '#', deduplicates and sorts tags.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SplitTags parses a space or comma separated tag list as typed by the user.
+func SplitTags(s string) []string {
+	return NormalizeTags(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	}))
+}
+
+// HasTag reports whether the task carries tag (case-insensitive).
+func (t Task) HasTag(tag string) bool {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	for _, have := range t.Tags {
+		if have == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// TitleWithTags renders the title with its tags appended as #tokens, the
+// inverse of ParseTags.
+func (t Task) TitleWithTags() string {
+	s := t.Title
+	for _, tag := range t.Tags {
+		s += " #" + tag
+	}
+	return s
+}
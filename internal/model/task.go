@@ -1,9 +1,44 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Task struct {
+	ID        string    `json:"id"`
 	Title     string    `json:"title"`
 	Completed bool      `json:"completed"`
 	CreatedAt time.Time `json:"created_at"`
+	Priority  Priority  `json:"priority,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+	Due       string    `json:"due,omitempty"` // time of day, HH:MM
+	Subtasks  []Subtask `json:"subtasks,omitempty"`
+
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	// Rollover bookkeeping, dates are YYYY-MM-DD
+	CarriedFrom string `json:"carried_from,omitempty"` // day the task was first scheduled
+	CarryCount  int    `json:"carry_count,omitempty"`  // times it was carried to a later day
+	CarriedTo   string `json:"carried_to,omitempty"`   // set on the original when copied forward
+
+	// RecurrenceID links an instance to the Recurrence it was created from
+	RecurrenceID string `json:"recurrence_id,omitempty"`
+}
+
+// SetCompleted updates the completion state and its timestamp.
+func (t *Task) SetCompleted(done bool) {
+	t.Completed = done
+	t.CompletedAt = nil
+	if done {
+		now := time.Now()
+		t.CompletedAt = &now
+	}
+}
+
+// NewID returns a fresh, time-ordered task identifier.
+func NewID() string {
+	return uuid.Must(uuid.NewV7()).String()
 }
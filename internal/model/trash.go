@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// TrashItem is a deleted task or script, kept until it is restored or
+// purged. Exactly one of Task and Script is set.
+type TrashItem struct {
+	ID        string    `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// Date is the day a task was deleted from, YYYY-MM-DD
+	Date   string  `json:"date,omitempty"`
+	Task   *Task   `json:"task,omitempty"`
+	Script *Script `json:"script,omitempty"`
+}
+
+// TrashTask wraps a task deleted from day at time at.
+func TrashTask(t Task, day, at time.Time) TrashItem {
+	return TrashItem{ID: NewID(), DeletedAt: at, Date: day.Format("2006-01-02"), Task: &t}
+}
+
+// TrashScript wraps a script deleted at time at.
+func TrashScript(s Script, at time.Time) TrashItem {
+	return TrashItem{ID: NewID(), DeletedAt: at, Script: &s}
+}
+
+// Title names the item in lists and messages.
+func (i TrashItem) Title() string {
+	if i.Task != nil {
+		return i.Task.Title
+	}
+	if i.Script != nil {
+		return i.Script.Name
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+	"zenith/internal/config"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Notification is a single reminder to show to the user.
+type Notification struct {
+	Title string
+	Body  string
+	Due   time.Time
+}
+
+// Notifier delivers notifications to the desktop or elsewhere.
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// New returns the notifier selected by cfg. With no notifier configured,
+// D-Bus is tried first and notify-send is used as a fallback.
+func New(cfg config.Config) (Notifier, error) {
+	switch cfg.Notifier {
+	case "":
+		if n, err := NewDBus(); err == nil {
+			return n, nil
+		}
+		return NewNotifySend()
+	case config.NotifierDBus:
+		return NewDBus()
+	case config.NotifierNotifySend:
+		return NewNotifySend()
+	case config.NotifierCommand:
+		if cfg.NotifyCommand == "" {
+			return nil, fmt.Errorf("notifier %q needs notify_command to be set", cfg.Notifier)
+		}
+		return Command{Command: cfg.NotifyCommand}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", cfg.Notifier)
+	}
+}
+
+// DBus sends notifications over the freedesktop.org notification spec.
+type DBus struct {
+	conn *dbus.Conn
+}
+
+func NewDBus() (*DBus, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("connect to session bus: %w", err)
+	}
+	return &DBus{conn: conn}, nil
+}
+
+func (d *DBus) Notify(n Notification) error {
+	obj := d.conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := obj.Call("org.freedesktop.Notifications.Notify", 0,
+		"Zenith",                  // app name
+		uint32(0),                 // replaces id
+		"",                        // icon
+		n.Title,                   // summary
+		n.Body,                    // body
+		[]string{},                // actions
+		map[string]dbus.Variant{}, // hints
+		int32(-1),                 // expire timeout, server default
+	)
+	return call.Err
+}
+
+// NotifySend shells out to the notify-send utility.
+type NotifySend struct {
+	path string
+}
+
+func NewNotifySend() (*NotifySend, error) {
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return nil, err
+	}
+	return &NotifySend{path: path}, nil
+}
+
+func (s *NotifySend) Notify(n Notification) error {
+	return exec.Command(s.path, "--app-name=Zenith", n.Title, n.Body).Run()
+}
+
+// Command runs a user supplied shell command for every notification. The
+// details are passed in the ZENITH_TITLE, ZENITH_BODY and ZENITH_DUE
+// environment variables.
+type Command struct {
+	Command string
+}
+
+func (c Command) Notify(n Notification) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", c.Command)
+	} else {
+		cmd = exec.Command("sh", "-c", c.Command)
+	}
+	cmd.Env = append(os.Environ(),
+		"ZENITH_TITLE="+n.Title,
+		"ZENITH_BODY="+n.Body,
+		"ZENITH_DUE="+n.Due.Format(time.RFC3339),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify command: %w: %s", err, out)
+	}
+	return nil
+}
+
+// Fake records notifications instead of showing them, for tests.
+type Fake struct {
+	mu   sync.Mutex
+	Sent []Notification
+	Err  error // returned from every Notify call when set
+}
+
+func (f *Fake) Notify(n Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Sent = append(f.Sent, n)
+	return nil
+}
@@ -0,0 +1,363 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"zenith/internal/dateparse"
+	"zenith/internal/model"
+)
+
+// Expr is a parsed query.
+type Expr interface {
+	// Match reports whether t, stored on day, satisfies the expression.
+	Match(t model.Task, day time.Time) bool
+	// String returns the expression in query syntax.
+	String() string
+}
+
+// All matches every task; it is what an empty query parses to.
+type All struct{}
+
+func (All) Match(model.Task, time.Time) bool { return true }
+func (All) String() string                   { return "" }
+
+type And struct{ Left, Right Expr }
+
+func (e And) Match(t model.Task, day time.Time) bool {
+	return e.Left.Match(t, day) && e.Right.Match(t, day)
+}
+func (e And) String() string { return e.Left.String() + " " + e.Right.String() }
+
+type Or struct{ Left, Right Expr }
+
+func (e Or) Match(t model.Task, day time.Time) bool {
+	return e.Left.Match(t, day) || e.Right.Match(t, day)
+}
+func (e Or) String() string { return "(" + e.Left.String() + " or " + e.Right.String() + ")" }
+
+type Not struct{ X Expr }
+
+func (e Not) Match(t model.Task, day time.Time) bool { return !e.X.Match(t, day) }
+func (e Not) String() string                         { return "-" + e.X.String() }
+
+// Text matches tasks whose title, notes, tags or subtasks contain Value,
+// ignoring case.
+type Text struct{ Value string }
+
+func (e Text) Match(t model.Task, _ time.Time) bool {
+	v := strings.ToLower(e.Value)
+	if contains(t.Title, v) || contains(t.Notes, v) {
+		return true
+	}
+	for _, tag := range t.Tags {
+		if contains(tag, v) {
+			return true
+		}
+	}
+	for _, s := range t.Subtasks {
+		if contains(s.Title, v) {
+			return true
+		}
+	}
+	return false
+}
+func (e Text) String() string { return quote(e.Value) }
+
+// Cond is a field:value term, e.g. priority:>=high.
+type Cond struct {
+	Field string
+	Op    string // "", "=", "!=", "<", "<=", ">", ">="
+	Value string
+
+	test func(t model.Task, day time.Time) bool
+}
+
+func (c Cond) Match(t model.Task, day time.Time) bool { return c.test(t, day) }
+func (c Cond) String() string                         { return c.Field + ":" + c.Op + quote(c.Value) }
+
+// Fields documents the fields a query can test.
+var Fields = [][2]string{
+	{"status:open|done", "completion"},
+	{"is:open|done|overdue|recurring|carried", "task state"},
+	{"tag:work", "carries the tag"},
+	{"priority:>=high", "none, low, medium, high, urgent; also p:"},
+	{"due:<tomorrow", "day of a timed task, or a time (due:<12:00), none, any"},
+	{"date:this-week", "the day the task is on; also day:"},
+	{"created:2026-10", "day the task was created"},
+	{"completed:>=-7d", "day the task was completed"},
+	{"title:word, notes:word", "text in one field"},
+	{"has:notes|subtasks|due|tags", "the field is not empty"},
+}
+
+// Dates in date fields are YYYY, YYYY-MM, anything dateparse understands,
+// or a period: week, month, year, optionally prefixed by this-, next- or
+// last-. "=" (the default) means within the period, "<" before it, "<="
+// before its end, ">" after it and ">=" from its start on.
+
+func newCond(field, op, value string, now time.Time) (Expr, error) {
+	c := Cond{Field: field, Op: op, Value: value}
+	bad := func(format string, args ...any) (Expr, error) {
+		return nil, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...))
+	}
+	// Fields limited to = and != leave != to the end, where it inverts
+	// their test.
+	negate := false
+	eqOnly := func() error {
+		if op != "" && op != "=" && op != "!=" {
+			return fmt.Errorf("%s: only = and != are supported", field)
+		}
+		negate = op == "!="
+		return nil
+	}
+	v := strings.ToLower(value)
+
+	switch field {
+	case "status", "is":
+		if err := eqOnly(); err != nil {
+			return nil, err
+		}
+		switch v {
+		case "open", "todo", "pending":
+			c.test = func(t model.Task, _ time.Time) bool { return !t.Completed }
+		case "done", "completed", "complete", "closed":
+			c.test = func(t model.Task, _ time.Time) bool { return t.Completed }
+		case "overdue":
+			c.test = func(t model.Task, day time.Time) bool { return overdue(t, day, now) }
+		case "recurring":
+			c.test = func(t model.Task, _ time.Time) bool { return t.RecurrenceID != "" }
+		case "carried":
+			c.test = func(t model.Task, _ time.Time) bool { return t.CarriedFrom != "" }
+		default:
+			return bad("unknown state %q", value)
+		}
+
+	case "tag", "tags":
+		if err := eqOnly(); err != nil {
+			return nil, err
+		}
+		tag := strings.TrimPrefix(v, "#")
+		c.test = func(t model.Task, _ time.Time) bool { return t.HasTag(tag) }
+
+	case "priority", "p":
+		p, err := model.ParsePriority(v)
+		if err != nil {
+			return bad("%v", err)
+		}
+		c.test = func(t model.Task, _ time.Time) bool { return compare(int(t.Priority), int(p), op) }
+
+	case "has":
+		if err := eqOnly(); err != nil {
+			return nil, err
+		}
+		switch v {
+		case "notes":
+			c.test = func(t model.Task, _ time.Time) bool { return t.Notes != "" }
+		case "subtasks":
+			c.test = func(t model.Task, _ time.Time) bool { return len(t.Subtasks) > 0 }
+		case "due":
+			c.test = func(t model.Task, _ time.Time) bool { return t.Due != "" }
+		case "tags":
+			c.test = func(t model.Task, _ time.Time) bool { return len(t.Tags) > 0 }
+		default:
+			return bad("unknown field %q", value)
+		}
+
+	case "title", "notes":
+		if err := eqOnly(); err != nil {
+			return nil, err
+		}
+		c.test = func(t model.Task, _ time.Time) bool {
+			if field == "title" {
+				return contains(t.Title, v)
+			}
+			return contains(t.Notes, v)
+		}
+
+	case "due":
+		switch v {
+		case "none", "any":
+			if err := eqOnly(); err != nil {
+				return nil, err
+			}
+		}
+		switch v {
+		case "none":
+			c.test = func(t model.Task, _ time.Time) bool { return t.Due == "" }
+		case "any":
+			c.test = func(t model.Task, _ time.Time) bool { return t.Due != "" }
+		default:
+			if clock, err := model.ParseClock(v); err == nil && strings.ContainsAny(v, ":apm") {
+				c.test = func(t model.Task, _ time.Time) bool {
+					return t.Due != "" && compareStrings(t.Due, clock, op)
+				}
+				break
+			}
+			per, err := parsePeriod(v, now)
+			if err != nil {
+				return bad("%v", err)
+			}
+			c.test = func(t model.Task, day time.Time) bool { return t.Due != "" && per.compare(day, op) }
+		}
+
+	case "date", "day", "on":
+		per, err := parsePeriod(v, now)
+		if err != nil {
+			return bad("%v", err)
+		}
+		c.test = func(_ model.Task, day time.Time) bool { return per.compare(day, op) }
+
+	case "created":
+		per, err := parsePeriod(v, now)
+		if err != nil {
+			return bad("%v", err)
+		}
+		c.test = func(t model.Task, _ time.Time) bool { return !t.CreatedAt.IsZero() && per.compare(t.CreatedAt, op) }
+
+	case "completed":
+		per, err := parsePeriod(v, now)
+		if err != nil {
+			return bad("%v", err)
+		}
+		c.test = func(t model.Task, _ time.Time) bool { return t.CompletedAt != nil && per.compare(*t.CompletedAt, op) }
+
+	default:
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	if negate {
+		test := c.test
+		c.test = func(t model.Task, day time.Time) bool { return !test(t, day) }
+	}
+	return c, nil
+}
+
+// overdue reports whether an open task is late: it is on a past day, or
+// on today with a due time that has passed.
+func overdue(t model.Task, day, now time.Time) bool {
+	if t.Completed {
+		return false
+	}
+	if at, ok := t.DueAt(day); ok {
+		return at.Before(now)
+	}
+	return startOfDay(day).Before(startOfDay(now))
+}
+
+// period is a span of days [start, end).
+type period struct {
+	start, end time.Time
+}
+
+var (
+	yearRe  = regexp.MustCompile(`^\d{4}$`)
+	monthRe = regexp.MustCompile(`^\d{4}-\d{2}$`)
+)
+
+func parsePeriod(s string, now time.Time) (period, error) {
+	today := startOfDay(now)
+	switch {
+	case yearRe.MatchString(s):
+		y, _ := strconv.Atoi(s)
+		start := time.Date(y, time.January, 1, 0, 0, 0, 0, time.Local)
+		return period{start, start.AddDate(1, 0, 0)}, nil
+	case monthRe.MatchString(s):
+		start, err := time.ParseInLocation("2006-01", s, time.Local)
+		if err != nil {
+			return period{}, err
+		}
+		return period{start, start.AddDate(0, 1, 0)}, nil
+	}
+
+	which, unit, found := strings.Cut(strings.ReplaceAll(s, " ", "-"), "-")
+	if !found {
+		which, unit = "this", s
+	}
+	shift := map[string]int{"this": 0, "next": 1, "last": -1}
+	if n, ok := shift[which]; ok {
+		switch unit {
+		case "week":
+			start := today.AddDate(0, 0, -((int(today.Weekday())+6)%7)+7*n)
+			return period{start, start.AddDate(0, 0, 7)}, nil
+		case "month":
+			start := time.Date(today.Year(), today.Month()+time.Month(n), 1, 0, 0, 0, 0, time.Local)
+			return period{start, start.AddDate(0, 1, 0)}, nil
+		case "year":
+			start := time.Date(today.Year()+n, time.January, 1, 0, 0, 0, 0, time.Local)
+			return period{start, start.AddDate(1, 0, 0)}, nil
+		}
+	}
+
+	d, err := dateparse.Parse(s, now)
+	if err != nil {
+		return period{}, err
+	}
+	return period{d, d.AddDate(0, 0, 1)}, nil
+}
+
+func (p period) compare(t time.Time, op string) bool {
+	d := startOfDay(t)
+	in := !d.Before(p.start) && d.Before(p.end)
+	switch op {
+	case "<":
+		return d.Before(p.start)
+	case "<=":
+		return d.Before(p.end)
+	case ">":
+		return !d.Before(p.end)
+	case ">=":
+		return !d.Before(p.start)
+	case "!=":
+		return !in
+	}
+	return in
+}
+
+func compare(a, b int, op string) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "!=":
+		return a != b
+	}
+	return a == b
+}
+
+func compareStrings(a, b, op string) bool {
+	return compare(strings.Compare(a, b), 0, op)
+}
+
+// IsPlainText reports whether e consists only of words, without fields or
+// operators, so that a caller may prefer its own text matching.
+func IsPlainText(e Expr) bool {
+	switch e := e.(type) {
+	case Text:
+		return true
+	case And:
+		return IsPlainText(e.Left) && IsPlainText(e.Right)
+	}
+	return false
+}
+
+func contains(s, lowerSub string) bool {
+	return strings.Contains(strings.ToLower(s), lowerSub)
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t()\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
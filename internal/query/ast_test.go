@@ -0,0 +1,186 @@
+package query
+
+import (
+	"testing"
+	"time"
+	"zenith/internal/model"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestMatch(t *testing.T) {
+	done := day(2026, time.March, 2).Add(10 * time.Hour)
+	report := model.Task{
+		Title:       "Write Report",
+		Notes:       "for the R&D review",
+		Tags:        []string{"work", "q1"},
+		Priority:    model.PriorityHigh,
+		Due:         "11:00",
+		Subtasks:    []model.Subtask{{Title: "collect numbers"}},
+		CreatedAt:   day(2025, time.October, 20),
+		Completed:   true,
+		CompletedAt: &done,
+	}
+	groceries := model.Task{Title: "groceries", Tags: []string{"home"}, CreatedAt: day(2026, time.March, 3)}
+	standup := model.Task{Title: "standup", Due: "09:30", RecurrenceID: "r", CarriedFrom: "2026-03-03"}
+
+	type on struct {
+		task model.Task
+		day  time.Time
+	}
+	today := day(2026, time.March, 4)
+	reportDone := on{report, day(2026, time.March, 2)}
+	groceriesToday := on{groceries, today}
+	standupToday := on{standup, today}
+	groceriesLate := on{groceries, day(2026, time.February, 27)}
+
+	tests := []struct {
+		query string
+		match []on
+		skip  []on
+	}{
+		{"report", []on{reportDone}, []on{groceriesToday}},
+		{"REPORT", []on{reportDone}, nil},
+		{"r&d", []on{reportDone}, nil},
+		{"numbers", []on{reportDone}, nil}, // subtask
+		{"Q1", []on{reportDone}, nil},      // tag
+		{`"write report"`, []on{reportDone}, nil},
+		{`"report write"`, nil, []on{reportDone}},
+		{"title:review", nil, []on{reportDone}},
+		{"notes:review", []on{reportDone}, nil},
+
+		{"report or groceries", []on{reportDone, groceriesToday}, []on{standupToday}},
+		{"report or groceries tag:work", []on{reportDone}, []on{groceriesToday}},
+		{"(report or groceries) tag:home", []on{groceriesToday}, []on{reportDone}},
+		{"-tag:work", []on{groceriesToday, standupToday}, []on{reportDone}},
+		{"not status:done", []on{groceriesToday}, []on{reportDone}},
+		{"-(groceries or standup)", []on{reportDone}, []on{groceriesToday, standupToday}},
+
+		{"status:open", []on{groceriesToday}, []on{reportDone}},
+		{"is:done", []on{reportDone}, []on{groceriesToday}},
+		{"is:recurring", []on{standupToday}, []on{groceriesToday}},
+		{"is:carried", []on{standupToday}, []on{groceriesToday}},
+		{"is:!=carried", []on{groceriesToday}, []on{standupToday}},
+		{"tag:#HOME", []on{groceriesToday}, []on{reportDone}},
+		{"tag:!=work", []on{groceriesToday}, []on{reportDone}},
+		{"has:!=notes", []on{groceriesToday}, []on{reportDone}},
+
+		{"priority:high", []on{reportDone}, []on{groceriesToday}},
+		{"p:>=medium", []on{reportDone}, []on{groceriesToday}},
+		{"p:<high", []on{groceriesToday}, []on{reportDone}},
+		{"p:none", []on{groceriesToday}, []on{reportDone}},
+
+		{"has:notes", []on{reportDone}, []on{groceriesToday}},
+		{"has:subtasks", []on{reportDone}, []on{groceriesToday}},
+		{"has:due", []on{standupToday}, []on{groceriesToday}},
+		{"-has:tags", []on{standupToday}, []on{groceriesToday}},
+
+		{"due:none", []on{groceriesToday}, []on{standupToday}},
+		{"due:any", []on{standupToday}, []on{groceriesToday}},
+		{"due:!=none", []on{standupToday}, []on{groceriesToday}},
+		{"due:<10:00", []on{standupToday}, []on{reportDone, groceriesToday}},
+		{"due:>=10am", []on{reportDone}, []on{standupToday}},
+		{"due:today", []on{standupToday}, []on{reportDone, groceriesToday}},
+
+		// 15:30 on a Wednesday: standup at 09:30 has passed, the groceries
+		// of a past day are late, done tasks never are.
+		{"is:overdue", []on{standupToday, groceriesLate}, []on{groceriesToday, reportDone}},
+
+		{"created:2025-10", []on{reportDone}, []on{groceriesToday}},
+		{"created:2025", []on{reportDone}, []on{groceriesToday}},
+		{"created:yesterday", []on{groceriesToday}, []on{reportDone, standupToday}}, // no creation time
+		{"completed:>=-7d", []on{reportDone}, []on{groceriesToday}},
+		{"completed:<this-week", nil, []on{reportDone}},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.query, now)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		for _, o := range tt.match {
+			if !e.Match(o.task, o.day) {
+				t.Errorf("%q does not match %q on %s", tt.query, o.task.Title, o.day.Format("2006-01-02"))
+			}
+		}
+		for _, o := range tt.skip {
+			if e.Match(o.task, o.day) {
+				t.Errorf("%q matches %q on %s", tt.query, o.task.Title, o.day.Format("2006-01-02"))
+			}
+		}
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	tests := []struct {
+		value      string
+		now        time.Time
+		start, end time.Time // [start, end)
+	}{
+		{"week", now, day(2026, time.March, 2), day(2026, time.March, 9)},
+		{"this-week", now, day(2026, time.March, 2), day(2026, time.March, 9)},
+		{"next-week", now, day(2026, time.March, 9), day(2026, time.March, 16)},
+		{"last week", now, day(2026, time.February, 23), day(2026, time.March, 2)},
+		{"this-week", day(2026, time.March, 8), day(2026, time.March, 2), day(2026, time.March, 9)}, // Sunday
+		{"month", now, day(2026, time.March, 1), day(2026, time.April, 1)},
+		{"last-month", now, day(2026, time.February, 1), day(2026, time.March, 1)},
+		{"next-month", day(2026, time.January, 31), day(2026, time.February, 1), day(2026, time.March, 1)},
+		{"next-month", day(2026, time.December, 15), day(2027, time.January, 1), day(2027, time.February, 1)},
+		{"year", now, day(2026, time.January, 1), day(2027, time.January, 1)},
+		{"last-year", now, day(2025, time.January, 1), day(2026, time.January, 1)},
+		{"2024", now, day(2024, time.January, 1), day(2025, time.January, 1)},
+		{"2024-02", now, day(2024, time.February, 1), day(2024, time.March, 1)},
+		{"today", now, day(2026, time.March, 4), day(2026, time.March, 5)},
+		{"fri", now, day(2026, time.March, 6), day(2026, time.March, 7)},
+		{"2026-03-10", now, day(2026, time.March, 10), day(2026, time.March, 11)},
+	}
+	for _, tt := range tests {
+		p, err := parsePeriod(tt.value, tt.now)
+		if err != nil {
+			t.Errorf("parsePeriod(%q): %v", tt.value, err)
+			continue
+		}
+		if !p.start.Equal(tt.start) || !p.end.Equal(tt.end) {
+			t.Errorf("parsePeriod(%q) at %s = [%s, %s), want [%s, %s)", tt.value, tt.now.Format("2006-01-02"),
+				p.start.Format("2006-01-02"), p.end.Format("2006-01-02"),
+				tt.start.Format("2006-01-02"), tt.end.Format("2006-01-02"))
+		}
+	}
+
+	for _, bad := range []string{"2024-13", "fortnight", "this-decade"} {
+		if _, err := parsePeriod(bad, now); err == nil {
+			t.Errorf("parsePeriod(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestPeriodOperators(t *testing.T) {
+	// The week of now runs from Monday March 2 to Sunday March 8.
+	before, first, after := day(2026, time.March, 1), day(2026, time.March, 2), day(2026, time.March, 9)
+	last := time.Date(2026, time.March, 8, 23, 0, 0, 0, time.Local)
+	tests := []struct {
+		op   string
+		want [4]bool // before, first, last, after
+	}{
+		{"", [4]bool{false, true, true, false}},
+		{"=", [4]bool{false, true, true, false}},
+		{"!=", [4]bool{true, false, false, true}},
+		{"<", [4]bool{true, false, false, false}},
+		{"<=", [4]bool{true, true, true, false}},
+		{">", [4]bool{false, false, false, true}},
+		{">=", [4]bool{false, true, true, true}},
+	}
+	for _, tt := range tests {
+		e, err := Parse("date:"+tt.op+"this-week", now)
+		if err != nil {
+			t.Fatalf("Parse(date:%sthis-week): %v", tt.op, err)
+		}
+		for i, d := range []time.Time{before, first, last, after} {
+			if got := e.Match(model.Task{}, d); got != tt.want[i] {
+				t.Errorf("date:%sthis-week on %s = %v, want %v", tt.op, d.Format("Mon 2006-01-02"), got, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokTerm
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+// token is a lexed piece of a query. Terms carry either a field, operator
+// and value ("priority:>=high") or just a value (a bare or quoted word).
+type token struct {
+	kind  tokenKind
+	pos   int // byte offset, for error messages
+	field string
+	op    string
+	value string
+}
+
+// lex splits a query into tokens. Words are separated by spaces and
+// parentheses; double quotes group a value containing either.
+func lex(s string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			toks = append(toks, token{kind: tokLParen, pos: i})
+			i++
+		case c == ')':
+			toks = append(toks, token{kind: tokRParen, pos: i})
+			i++
+		case c == '-' && i+1 < len(s) && s[i+1] != ' ':
+			toks = append(toks, token{kind: tokNot, pos: i})
+			i++
+		case c == '"':
+			value, n, err := quoted(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("at %d: %w", i, err)
+			}
+			toks = append(toks, token{kind: tokTerm, pos: i, value: value})
+			i += n
+		default:
+			tok, n, err := word(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("at %d: %w", i, err)
+			}
+			tok.pos = i
+			toks = append(toks, tok)
+			i += n
+		}
+	}
+	return append(toks, token{kind: tokEOF, pos: len(s)}), nil
+}
+
+// word reads a keyword, a bare word or a field:value term.
+func word(s string) (token, int, error) {
+	end := strings.IndexAny(s, " \t\n()")
+	if end < 0 {
+		end = len(s)
+	}
+	w := s[:end]
+
+	switch strings.ToLower(w) {
+	case "and", "&&":
+		return token{kind: tokAnd}, end, nil
+	case "or", "||", "|":
+		return token{kind: tokOr}, end, nil
+	case "not", "!":
+		return token{kind: tokNot}, end, nil
+	}
+
+	field, rest, ok := strings.Cut(w, ":")
+	if !ok || field == "" || !isIdent(field) {
+		return token{kind: tokTerm, value: w}, end, nil
+	}
+
+	n := len(field) + 1
+	op := operator(rest)
+	n += len(op)
+	if n < len(s) && s[n] == '"' {
+		value, q, err := quoted(s[n:])
+		if err != nil {
+			return token{}, 0, err
+		}
+		return token{kind: tokTerm, field: strings.ToLower(field), op: op, value: value}, n + q, nil
+	}
+	return token{kind: tokTerm, field: strings.ToLower(field), op: op, value: w[n:]}, end, nil
+}
+
+// operator returns the comparison operator at the start of a field value.
+func operator(s string) string {
+	for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// quoted reads a double quoted string starting at s[0] and returns its
+// contents and the number of bytes consumed. \" and \\ are escapes.
+func quoted(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quote")
+}
+
+func isIdent(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && r != '_' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+// Package query parses and evaluates task filters such as
+//
+//	status:open tag:work priority:>=high due:<tomorrow created:2026-10
+//
+// Terms next to each other must all match; "or" and parentheses group
+// alternatives and "-" or "not" negates a term. Words without a field match
+// the title, notes, tags and subtasks. See Fields for the supported fields.
+package query
+
+import (
+	"fmt"
+	"time"
+)
+
+// Parse reads a query. Relative dates in it ("today", "next fri") are
+// resolved against now. An empty query matches every task.
+func Parse(s string, now time.Time) (Expr, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	p := &parser{toks: toks, now: now}
+	if p.peek().kind == tokEOF {
+		return All{}, nil
+	}
+	e, err := p.or()
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("query: unexpected %s at %d", describe(t), t.pos)
+	}
+	return e, nil
+}
+
+type parser struct {
+	toks []token
+	i    int
+	now  time.Time
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// or := and ("or" and)*
+func (p *parser) or() (Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{left, right}
+	}
+	return left, nil
+}
+
+// and := unary (["and"] unary)*
+func (p *parser) and() (Expr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokTerm, tokNot, tokLParen:
+		default:
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{left, right}
+	}
+}
+
+// unary := ("-" | "not") unary | "(" or ")" | term
+func (p *parser) unary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNot:
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{x}, nil
+	case tokLParen:
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.next(); c.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at %d, found %s", c.pos, describe(c))
+		}
+		return e, nil
+	case tokTerm:
+		if t.field == "" {
+			return Text{Value: t.value}, nil
+		}
+		return newCond(t.field, t.op, t.value, p.now)
+	default:
+		return nil, fmt.Errorf("unexpected %s at %d", describe(t), t.pos)
+	}
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokAnd:
+		return `"and"`
+	case tokOr:
+		return `"or"`
+	case tokNot:
+		return `"not"`
+	case tokLParen:
+		return "("
+	case tokRParen:
+		return ")"
+	}
+	if t.field != "" {
+		return fmt.Sprintf("%s:%s%s", t.field, t.op, t.value)
+	}
+	return fmt.Sprintf("%q", t.value)
+}
@@ -0,0 +1,109 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, time.March, 4, 15, 30, 0, 0, time.Local) // a Wednesday
+
+func TestParseStructure(t *testing.T) {
+	tests := []struct {
+		in, want string // want is the String of the parsed expression
+	}{
+		{"", ""},
+		{"a", "a"},
+		{"a b", "a b"},
+		{"a and b", "a b"},
+		{"a or b", "(a or b)"},
+		// Adjacent terms bind tighter than or.
+		{"a or b c", "(a or b c)"},
+		{"a b or c", "(a b or c)"},
+		{"a or b or c", "((a or b) or c)"},
+		{"(a or b) c", "(a or b) c"},
+		{"A || b && c", "(A or b c)"},
+
+		{"-a", "-a"},
+		{"not a", "-a"},
+		{"! a", "-a"},
+		{"-a b", "-a b"},
+		{"-(a or b)", "-(a or b)"},
+		{"not not a", "--a"},
+		{"-tag:work", "-tag:work"},
+		{"well-known", "well-known"},
+		{"a - b", "a - b"},
+
+		{`"write report"`, `"write report"`},
+		{`"say \"hi\""`, `"say \"hi\""`},
+		{`"(or)" x`, `"(or)" x`},
+		{`title:"weekly sync"`, `title:"weekly sync"`},
+		{`notes:"a b" c`, `notes:"a b" c`},
+		{"Tag:Work", "tag:Work"},
+		{"priority:>=high", "priority:>=high"},
+		{"p:!=low", "p:!=low"},
+		{`"http://example.com"`, "http://example.com"},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.in, now)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got := e.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		"(a",
+		"a)",
+		"()",
+		"or a",
+		"a or",
+		"a and",
+		"not",
+		`"unterminated`,
+		`title:"unterminated`,
+		"colour:red",
+		"priority:huge",
+		"status:<open",
+		"is:late",
+		"tag:>work",
+		"has:colour",
+		"date:someday",
+		"due:<soon",
+		"due:>none",
+	} {
+		if e, err := Parse(in, now); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", in, e)
+		}
+	}
+}
+
+func TestIsPlainText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"report", true},
+		{"write report", true},
+		{`"write report" draft`, true},
+		{"a and b", true},
+		{"", false},
+		{"a or b", false},
+		{"-draft", false},
+		{"report tag:work", false},
+		{"(a b)", true},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.in, now)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.in, err)
+		}
+		if got := IsPlainText(e); got != tt.want {
+			t.Errorf("IsPlainText(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
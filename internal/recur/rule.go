@@ -0,0 +1,345 @@
+package recur
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Freq int
+
+const (
+	Daily Freq = iota
+	Weekly
+	Monthly
+)
+
+var freqNames = map[Freq]string{Daily: "DAILY", Weekly: "WEEKLY", Monthly: "MONTHLY"}
+
+// Rule describes when a task repeats, relative to the day it starts. It
+// covers a subset of RFC 5545 RRULE: FREQ (DAILY, WEEKLY, MONTHLY),
+// INTERVAL, BYDAY for weekly rules and BYMONTHDAY for monthly rules.
+type Rule struct {
+	Freq     Freq
+	Interval int            // repeat every Interval days/weeks/months, at least 1
+	Weekdays []time.Weekday // weekly: days of the week, empty means the start's weekday
+	MonthDay int            // monthly: day of the month, 0 means the start's day
+}
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+var weekdayNames = map[string]time.Weekday{
+	"su": time.Sunday, "sun": time.Sunday, "sunday": time.Sunday,
+	"mo": time.Monday, "mon": time.Monday, "monday": time.Monday,
+	"tu": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"we": time.Wednesday, "wed": time.Wednesday, "wednesday": time.Wednesday,
+	"th": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fr": time.Friday, "fri": time.Friday, "friday": time.Friday,
+	"sa": time.Saturday, "sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseWeekday accepts English weekday names and their common abbreviations.
+func ParseWeekday(s string) (time.Weekday, bool) {
+	wd, ok := weekdayNames[strings.ToLower(s)]
+	return wd, ok
+}
+
+// Parse reads a rule in either RRULE form ("FREQ=WEEKLY;BYDAY=MO,WE") or
+// one of the short forms:
+//
+//	daily, weekdays, every 3 days
+//	weekly, weekly on mon,wed, every 2 weeks on fri
+//	monthly, monthly on 15, every 3 months on 1
+func Parse(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	upper := strings.ToUpper(s)
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"))
+	}
+	return parseShort(strings.ToLower(s))
+}
+
+func parseShort(s string) (Rule, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 0 {
+		return Rule{}, fmt.Errorf("empty recurrence rule")
+	}
+
+	r := Rule{Interval: 1}
+	rest := fields[1:]
+	switch fields[0] {
+	case "daily":
+		r.Freq = Daily
+	case "weekly":
+		r.Freq = Weekly
+	case "monthly":
+		r.Freq = Monthly
+	case "weekdays":
+		r.Freq = Weekly
+		r.Weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	case "every":
+		if len(rest) == 0 {
+			return Rule{}, fmt.Errorf("every what?")
+		}
+		if n, err := strconv.Atoi(rest[0]); err == nil {
+			if n < 1 {
+				return Rule{}, fmt.Errorf("interval must be at least 1")
+			}
+			r.Interval = n
+			rest = rest[1:]
+		}
+		if len(rest) == 0 {
+			return Rule{}, fmt.Errorf("every %d what?", r.Interval)
+		}
+		switch strings.TrimSuffix(rest[0], "s") {
+		case "day":
+			r.Freq = Daily
+		case "week":
+			r.Freq = Weekly
+		case "month":
+			r.Freq = Monthly
+		case "weekday":
+			if r.Interval > 1 {
+				return Rule{}, fmt.Errorf("every %d weekdays is not supported", r.Interval)
+			}
+			if len(rest) > 1 {
+				return Rule{}, fmt.Errorf("unexpected %q", strings.Join(rest[1:], " "))
+			}
+			return parseShort("weekdays")
+		default:
+			// "every mon,wed" is shorthand for weekly
+			if _, ok := ParseWeekday(rest[0]); !ok {
+				return Rule{}, fmt.Errorf("unknown unit %q", rest[0])
+			}
+			r.Freq = Weekly
+			return r, r.parseWeekdays(rest)
+		}
+		rest = rest[1:]
+	default:
+		return Rule{}, fmt.Errorf("unknown recurrence %q", fields[0])
+	}
+
+	if len(rest) > 0 && rest[0] == "on" {
+		rest = rest[1:]
+	}
+	if len(rest) > 0 && rest[0] == "the" {
+		rest = rest[1:]
+	}
+	if len(rest) == 0 {
+		return r, nil
+	}
+
+	switch r.Freq {
+	case Weekly:
+		return r, r.parseWeekdays(rest)
+	case Monthly:
+		if len(rest) != 1 {
+			return Rule{}, fmt.Errorf("expected a single day of the month")
+		}
+		day := strings.TrimRight(rest[0], "stndrh") // 1st, 2nd, 3rd, 15th
+		n, err := strconv.Atoi(day)
+		if err != nil || n < 1 || n > 31 {
+			return Rule{}, fmt.Errorf("invalid day of month %q", rest[0])
+		}
+		r.MonthDay = n
+		return r, nil
+	default:
+		return Rule{}, fmt.Errorf("unexpected %q", strings.Join(rest, " "))
+	}
+}
+
+func (r *Rule) parseWeekdays(names []string) error {
+	for _, name := range names {
+		wd, ok := ParseWeekday(name)
+		if !ok {
+			return fmt.Errorf("unknown weekday %q", name)
+		}
+		r.Weekdays = append(r.Weekdays, wd)
+	}
+	return nil
+}
+
+func parseRRule(s string) (Rule, error) {
+	r := Rule{Interval: 1}
+	freqSet := false
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("invalid rule part %q", part)
+		}
+		switch key {
+		case "FREQ":
+			found := false
+			for f, name := range freqNames {
+				if name == value {
+					r.Freq, found = f, true
+				}
+			}
+			if !found {
+				return Rule{}, fmt.Errorf("unsupported FREQ %q", value)
+			}
+			freqSet = true
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				wd, ok := ParseWeekday(code)
+				if !ok || len(code) != 2 {
+					return Rule{}, fmt.Errorf("invalid BYDAY %q", code)
+				}
+				r.Weekdays = append(r.Weekdays, wd)
+			}
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 31 {
+				return Rule{}, fmt.Errorf("invalid BYMONTHDAY %q", value)
+			}
+			r.MonthDay = n
+		default:
+			return Rule{}, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+	if !freqSet {
+		return Rule{}, fmt.Errorf("rule has no FREQ")
+	}
+	if len(r.Weekdays) > 0 && r.Freq != Weekly {
+		return Rule{}, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if r.MonthDay > 0 && r.Freq != Monthly {
+		return Rule{}, fmt.Errorf("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	return r, nil
+}
+
+// String returns the rule in RRULE form, which Parse reads back.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + freqNames[r.Freq]}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.Weekdays) > 0 {
+		codes := make([]string, len(r.Weekdays))
+		for i, wd := range r.Weekdays {
+			codes[i] = weekdayCodes[wd]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.MonthDay > 0 {
+		parts = append(parts, fmt.Sprintf("BYMONTHDAY=%d", r.MonthDay))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe returns a short human readable form, e.g. "every 2 weeks on Mon, Fri".
+func (r Rule) Describe() string {
+	if r.Freq == Weekly && r.Interval <= 1 && isWeekdays(r.Weekdays) {
+		return "every weekday"
+	}
+	units := map[Freq]string{Daily: "day", Weekly: "week", Monthly: "month"}
+	s := "every " + units[r.Freq]
+	if r.Interval > 1 {
+		s = fmt.Sprintf("every %d %ss", r.Interval, units[r.Freq])
+	}
+	if len(r.Weekdays) > 0 {
+		names := make([]string, len(r.Weekdays))
+		for i, wd := range r.Weekdays {
+			names[i] = wd.String()[:3]
+		}
+		s += " on " + strings.Join(names, ", ")
+	}
+	if r.MonthDay > 0 {
+		s += fmt.Sprintf(" on day %d", r.MonthDay)
+	}
+	return s
+}
+
+func isWeekdays(days []time.Weekday) bool {
+	if len(days) != 5 {
+		return false
+	}
+	for _, wd := range days {
+		if wd == time.Saturday || wd == time.Sunday {
+			return false
+		}
+	}
+	return true
+}
+
+// Occurs reports whether a rule starting on start has an occurrence on day d.
+// Only the calendar dates of start and d matter. Monthly rules on a day the
+// month does not have (e.g. the 31st) fall on the month's last day.
+func (r Rule) Occurs(start, d time.Time) bool {
+	start, d = date(start), date(d)
+	if d.Before(start) {
+		return false
+	}
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch r.Freq {
+	case Daily:
+		return daysBetween(start, d)%interval == 0
+
+	case Weekly:
+		days := r.Weekdays
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		match := false
+		for _, wd := range days {
+			if d.Weekday() == wd {
+				match = true
+			}
+		}
+		if !match {
+			return false
+		}
+		// Count whole weeks between the Mondays starting each week
+		weeks := daysBetween(monday(start), monday(d)) / 7
+		return weeks%interval == 0
+
+	case Monthly:
+		months := (d.Year()-start.Year())*12 + int(d.Month()-start.Month())
+		if months%interval != 0 {
+			return false
+		}
+		want := r.MonthDay
+		if want == 0 {
+			want = start.Day()
+		}
+		if last := daysIn(d.Year(), d.Month()); want > last {
+			want = last
+		}
+		return d.Day() == want
+	}
+	return false
+}
+
+// date drops the clock and zone so day arithmetic is not affected by DST.
+func date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func monday(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
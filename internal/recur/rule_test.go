@@ -0,0 +1,176 @@
+package recur
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rule
+	}{
+		{"daily", Rule{Freq: Daily, Interval: 1}},
+		{"weekly", Rule{Freq: Weekly, Interval: 1}},
+		{"monthly", Rule{Freq: Monthly, Interval: 1}},
+		{"weekdays", Rule{Freq: Weekly, Interval: 1, Weekdays: weekdays}},
+		{"every weekday", Rule{Freq: Weekly, Interval: 1, Weekdays: weekdays}},
+		{"every 1 weekday", Rule{Freq: Weekly, Interval: 1, Weekdays: weekdays}},
+		{"every 3 days", Rule{Freq: Daily, Interval: 3}},
+		{"every day", Rule{Freq: Daily, Interval: 1}},
+		{"Weekly on Mon, Wed", Rule{Freq: Weekly, Interval: 1, Weekdays: []time.Weekday{time.Monday, time.Wednesday}}},
+		{"every 2 weeks on fri", Rule{Freq: Weekly, Interval: 2, Weekdays: []time.Weekday{time.Friday}}},
+		{"every tue,thu", Rule{Freq: Weekly, Interval: 1, Weekdays: []time.Weekday{time.Tuesday, time.Thursday}}},
+		{"monthly on 15", Rule{Freq: Monthly, Interval: 1, MonthDay: 15}},
+		{"every 3 months on the 1st", Rule{Freq: Monthly, Interval: 3, MonthDay: 1}},
+		{"FREQ=WEEKLY;BYDAY=MO,WE", Rule{Freq: Weekly, Interval: 1, Weekdays: []time.Weekday{time.Monday, time.Wednesday}}},
+		{"RRULE:FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=31", Rule{Freq: Monthly, Interval: 2, MonthDay: 31}},
+		{"freq=daily;interval=4", Rule{Freq: Daily, Interval: 4}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"yearly",
+		"every",
+		"every 0 days",
+		"every 3",
+		"every 2 weekdays",
+		"every weekday on mon",
+		"every 2 fortnights",
+		"weekly on someday",
+		"monthly on 32",
+		"monthly on 1 15",
+		"daily on mon",
+		"FREQ=YEARLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=WEEKLY;BYDAY=MON",
+		"FREQ=DAILY;COUNT=3",
+	} {
+		if r, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", in, r)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for _, in := range []string{
+		"daily",
+		"every 3 days",
+		"weekdays",
+		"every 2 weeks on mon,fri",
+		"monthly",
+		"every 6 months on 31",
+		"FREQ=WEEKLY;INTERVAL=3;BYDAY=SU,SA",
+	} {
+		r, err := Parse(in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", in, err)
+		}
+		back, err := Parse(r.String())
+		if err != nil {
+			t.Errorf("Parse(%q) of %q: %v", r.String(), in, err)
+			continue
+		}
+		if !reflect.DeepEqual(back, r) {
+			t.Errorf("%q: String() = %q reads back as %+v, want %+v", in, r.String(), back, r)
+		}
+	}
+}
+
+func day(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestOccurs(t *testing.T) {
+	tests := []struct {
+		rule  string
+		start string
+		on    []string
+		off   []string
+	}{
+		{
+			rule: "every 3 days", start: "2026-01-30",
+			on:  []string{"2026-01-30", "2026-02-02", "2026-03-01"},
+			off: []string{"2026-01-29", "2026-01-31", "2026-02-01"},
+		},
+		{
+			rule: "weekly", start: "2026-03-04", // a Wednesday
+			on:  []string{"2026-03-04", "2026-03-11"},
+			off: []string{"2026-03-05", "2026-02-25"},
+		},
+		{
+			rule: "weekdays", start: "2026-03-06", // a Friday
+			on:  []string{"2026-03-06", "2026-03-09", "2026-03-13"},
+			off: []string{"2026-03-07", "2026-03-08", "2026-03-05"},
+		},
+		{
+			// Weeks count from the Monday of the start's week, so the
+			// Monday after a Saturday start is already in week one.
+			rule: "every 2 weeks on mon,sat", start: "2026-03-07", // a Saturday
+			on:  []string{"2026-03-07", "2026-03-16", "2026-03-21"},
+			off: []string{"2026-03-09", "2026-03-14", "2026-03-23"},
+		},
+		{
+			rule: "monthly on 31", start: "2026-01-31",
+			on:  []string{"2026-01-31", "2026-02-28", "2026-04-30", "2026-05-31"},
+			off: []string{"2026-02-27", "2026-04-29", "2026-03-30"},
+		},
+		{
+			rule: "monthly", start: "2024-01-30",
+			on:  []string{"2024-02-29", "2024-03-30", "2025-02-28"},
+			off: []string{"2024-02-28", "2024-03-29", "2025-03-01"},
+		},
+		{
+			rule: "every 2 months on 15", start: "2026-11-15",
+			on:  []string{"2027-01-15", "2027-03-15"},
+			off: []string{"2026-12-15", "2027-02-15", "2027-01-14"},
+		},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.rule)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.rule, err)
+		}
+		for _, d := range tt.on {
+			if !r.Occurs(day(tt.start), day(d)) {
+				t.Errorf("%q from %s: no occurrence on %s", tt.rule, tt.start, d)
+			}
+		}
+		for _, d := range tt.off {
+			if r.Occurs(day(tt.start), day(d)) {
+				t.Errorf("%q from %s: unexpected occurrence on %s", tt.rule, tt.start, d)
+			}
+		}
+	}
+}
+
+func TestOccursIgnoresClock(t *testing.T) {
+	r := Rule{Freq: Daily, Interval: 2}
+	loc := time.FixedZone("UTC-11", -11*3600)
+	start := time.Date(2026, 3, 1, 23, 30, 0, 0, loc)
+	if !r.Occurs(start, time.Date(2026, 3, 3, 0, 15, 0, 0, time.UTC)) {
+		t.Error("the clock and zone of start and d changed the result")
+	}
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// rename is os.Rename; tests replace it to make a rename fail.
+var rename = os.Rename
+
+// writeFileAtomic replaces path with data so that readers (and a crash) only
+// ever observe the old or the new contents: the data is written to a temp
+// file in the same directory, fsynced, then renamed over path. With backup
+// set, the previous version is kept as path.bak.
+func writeFileAtomic(path string, data []byte, perm os.FileMode, backup bool) error {
+	if backup {
+		if err := backupFile(path, perm); err != nil {
+			return err
+		}
+	}
+
+	tmpName, err := writeTemp(path, data, perm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpName) // no-op once renamed
+
+	if err := rename(tmpName, path); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// writeFilesAtomic replaces several files together. Every new version is
+// written to a temp file before any of them is renamed into place, and if a
+// rename fails the files already replaced are restored, so a failure leaves
+// either all of the new contents or all of the old ones.
+func writeFilesAtomic(files map[string][]byte, perm os.FileMode, backup bool) error {
+	type pending struct {
+		path, tmp string
+		old       []byte // nil if path did not exist
+	}
+	var staged []pending
+	defer func() {
+		for _, p := range staged {
+			os.Remove(p.tmp) // no-op once renamed
+		}
+	}()
+
+	for path, data := range files {
+		old, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		tmp, err := writeTemp(path, data, perm)
+		if err != nil {
+			return err
+		}
+		staged = append(staged, pending{path: path, tmp: tmp, old: old})
+	}
+
+	if backup {
+		for _, p := range staged {
+			if err := backupFile(p.path, perm); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, p := range staged {
+		if err := rename(p.tmp, p.path); err != nil {
+			for _, done := range staged[:i] {
+				if done.old == nil {
+					os.Remove(done.path)
+				} else {
+					writeFileAtomic(done.path, done.old, perm, false)
+				}
+			}
+			return err
+		}
+	}
+	for _, p := range staged {
+		syncDir(filepath.Dir(p.path))
+	}
+	return nil
+}
+
+// writeTemp writes data to a synced temp file next to path and returns its name.
+func writeTemp(path string, data []byte, perm os.FileMode) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	name := tmp.Name()
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(name, perm)
+	}
+	if err != nil {
+		os.Remove(name)
+		return "", err
+	}
+	return name, nil
+}
+
+// backupFile copies the current contents of path to path.bak, replacing the
+// previous backup. A missing path is not an error.
+func backupFile(path string, perm os.FileMode) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path+".bak", data, perm, false)
+}
+
+// syncDir flushes the directory entry after a rename. Not every platform
+// supports fsync on directories, so failures are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
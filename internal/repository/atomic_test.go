@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failRename makes the nth rename from now on fail, then restores rename
+// when the test ends.
+func failRename(t *testing.T, n int) {
+	t.Helper()
+	calls := 0
+	rename = func(from, to string) error {
+		calls++
+		if calls == n {
+			return errWrite
+		}
+		return os.Rename(from, to)
+	}
+	t.Cleanup(func() { rename = os.Rename })
+}
+
+func expectFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("read %s: %v", filepath.Base(path), err)
+		return
+	}
+	if string(data) != want {
+		t.Errorf("%s holds %q, want %q", filepath.Base(path), data, want)
+	}
+}
+
+func expectMissing(t *testing.T, path string) {
+	t.Helper()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("%s exists, want it missing (%v)", filepath.Base(path), err)
+	}
+}
+
+// expectNoTemp fails if a temp file was left behind in dir.
+func expectNoTemp(t *testing.T, dir string) {
+	t.Helper()
+	if tmp, _ := filepath.Glob(filepath.Join(dir, ".*.tmp")); len(tmp) > 0 {
+		t.Errorf("temp files left behind: %v", tmp)
+	}
+}
+
+func TestWriteFileAtomicBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	for _, v := range []string{"one", "two", "three"} {
+		if err := writeFileAtomic(path, []byte(v), 0644, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectFile(t, path, "three")
+	expectFile(t, path+".bak", "two")
+
+	if err := writeFileAtomic(path, []byte("four"), 0644, false); err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, path+".bak", "two") // not rotated without backup
+	expectNoTemp(t, filepath.Dir(path))
+}
+
+func TestWriteFileAtomicFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	if err := writeFileAtomic(path, []byte("old"), 0644, false); err != nil {
+		t.Fatal(err)
+	}
+	failRename(t, 1)
+	if err := writeFileAtomic(path, []byte("new"), 0644, false); !errors.Is(err, errWrite) {
+		t.Fatalf("writeFileAtomic = %v, want the rename error", err)
+	}
+	expectFile(t, path, "old")
+	expectNoTemp(t, filepath.Dir(path))
+}
+
+func TestWriteFilesAtomic(t *testing.T) {
+	dir := t.TempDir()
+	a, b, c := filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")
+	for _, path := range []string{a, b} {
+		if err := writeFileAtomic(path, []byte("old "+filepath.Base(path)), 0644, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string][]byte{a: []byte("new a"), b: []byte("new b"), c: []byte("new c")}
+
+	// The first rename goes through and the second fails, so whichever
+	// file was replaced first has to be put back.
+	failRename(t, 2)
+	if err := writeFilesAtomic(files, 0644, false); !errors.Is(err, errWrite) {
+		t.Fatalf("writeFilesAtomic = %v, want the rename error", err)
+	}
+	expectFile(t, a, "old a")
+	expectFile(t, b, "old b")
+	expectMissing(t, c)
+	expectNoTemp(t, dir)
+
+	rename = os.Rename
+	if err := writeFilesAtomic(files, 0644, true); err != nil {
+		t.Fatal(err)
+	}
+	for path, data := range files {
+		expectFile(t, path, string(data))
+	}
+	expectFile(t, a+".bak", "old a")
+	expectFile(t, b+".bak", "old b")
+	expectMissing(t, c+".bak")
+	expectNoTemp(t, dir)
+}
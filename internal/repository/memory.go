@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"zenith/internal/model"
+)
+
+// MemoryStore is a Store that lives only in memory, for tests and previews.
+type MemoryStore struct {
+	mu      sync.Mutex
+	days    map[string][]model.Task
+	scripts []model.Script
+	recs    []model.Recurrence
+	filters []model.Filter
+	trash   []model.TrashItem
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		days:    make(map[string][]model.Task),
+		scripts: DefaultScripts(),
+		filters: DefaultFilters(),
+	}
+}
+
+func (s *MemoryStore) Close() error { return nil }
+
+func (s *MemoryStore) LoadTasks(d time.Time) ([]model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := append([]model.Task{}, s.days[dayKey(d)]...)
+	backfillIDs(d, tasks)
+	return materialize(d, tasks, s.recs), nil
+}
+
+func (s *MemoryStore) SaveTasks(d time.Time, tasks []model.Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.days[dayKey(d)] = append([]model.Task{}, tasks...)
+	return nil
+}
+
+func (s *MemoryStore) SaveDays(days []DayTasks) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, day := range days {
+		s.days[dayKey(day.Date)] = append([]model.Task{}, day.Tasks...)
+	}
+	return nil
+}
+
+func (s *MemoryStore) LoadScripts() ([]model.Script, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.Script{}, s.scripts...), nil
+}
+
+func (s *MemoryStore) SaveScripts(scripts []model.Script) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts = append([]model.Script{}, scripts...)
+	return nil
+}
+
+func (s *MemoryStore) LoadRecurrences() ([]model.Recurrence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.Recurrence{}, s.recs...), nil
+}
+
+func (s *MemoryStore) SaveRecurrences(recs []model.Recurrence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recs = append([]model.Recurrence{}, recs...)
+	return nil
+}
+
+func (s *MemoryStore) LoadFilters() ([]model.Filter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.Filter{}, s.filters...), nil
+}
+
+func (s *MemoryStore) SaveFilters(filters []model.Filter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filters = append([]model.Filter{}, filters...)
+	return nil
+}
+
+func (s *MemoryStore) LoadTrash() ([]model.TrashItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.TrashItem{}, s.trash...), nil
+}
+
+func (s *MemoryStore) SaveTrash(items []model.TrashItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trash = append([]model.TrashItem{}, items...)
+	return nil
+}
+
+func (s *MemoryStore) Dates() ([]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var dates []time.Time
+	for key := range s.days {
+		d, err := parseDayKey(key)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, d)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates, nil
+}
+
+func (s *MemoryStore) Query(q Query) ([]DatedTask, error) {
+	dates, err := s.Dates()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []DatedTask
+	for _, d := range dates {
+		if !q.includesDay(d) {
+			continue
+		}
+		tasks := append([]model.Task{}, s.days[dayKey(d)]...)
+		backfillIDs(d, tasks)
+		for _, t := range tasks {
+			dt := DatedTask{Date: d, Task: t}
+			if q.matches(dt) {
+				out = append(out, dt)
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"zenith/internal/model"
+)
+
+// MigrateOptions controls Migrate.
+type MigrateOptions struct {
+	DryRun bool
+	// Force allows overwriting days that already hold tasks in the
+	// destination, and scripts, recurring tasks, filters or trash of its own
+	Force bool
+}
+
+// MigrationReport summarises what Migrate copied, or would copy on a dry run.
+type MigrationReport struct {
+	Days        int
+	Tasks       int
+	Scripts     int
+	Recurrences int
+	Filters     int
+	Trash       int
+	Checksum    string // sha256 over everything copied
+	// Conflicts lists days that already hold tasks in the destination
+	Conflicts []time.Time
+	// DataConflicts names what else the destination holds that the copy
+	// would replace: "scripts", "recurring tasks", "filters" or "trash".
+	// Defaults a fresh store starts with do not count.
+	DataConflicts []string
+}
+
+// contents is everything a store holds, as read for a migration.
+type contents struct {
+	days    []DayTasks
+	scripts []model.Script
+	recs    []model.Recurrence
+	filters []model.Filter
+	trash   []model.TrashItem
+}
+
+// inspector is implemented by stores whose reads can change what is stored,
+// as the JSON store moves corrupt files aside. inspect returns a view of the
+// same data that only reads.
+type inspector interface {
+	inspect() Store
+}
+
+func readOnly(s Store) Store {
+	if i, ok := s.(inspector); ok {
+		return i.inspect()
+	}
+	return s
+}
+
+// Migrate copies every day, the scripts, the recurrences, the saved filters
+// and the trash from src to dst, then reads them back from dst and checks
+// that counts and checksums match the source. The source is only read, even
+// where it is corrupt, and nothing is written on a dry run.
+func Migrate(src, dst Store, opts MigrateOptions) (MigrationReport, error) {
+	var report MigrationReport
+
+	// Read everything up front so a bad source file aborts before any write.
+	c, err := snapshot(src)
+	if err != nil {
+		return report, fmt.Errorf("source: %w", err)
+	}
+	report.Days = len(c.days)
+	for _, day := range c.days {
+		report.Tasks += len(day.Tasks)
+	}
+	report.Scripts = len(c.scripts)
+	report.Recurrences = len(c.recs)
+	report.Filters = len(c.filters)
+	report.Trash = len(c.trash)
+	if report.Checksum, err = checksum(c); err != nil {
+		return report, err
+	}
+
+	existing, err := dst.Dates()
+	if err != nil {
+		return report, fmt.Errorf("destination: %w", err)
+	}
+	var have contents
+	if err := readRest(readOnly(dst), &have); err != nil {
+		return report, fmt.Errorf("destination: %w", err)
+	}
+	report.DataConflicts = dataConflicts(have, c)
+	taken := make(map[string]bool, len(existing))
+	for _, d := range existing {
+		taken[dayKey(d)] = true
+	}
+	for _, day := range c.days {
+		if taken[dayKey(day.Date)] {
+			report.Conflicts = append(report.Conflicts, day.Date)
+		}
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+	if (len(report.Conflicts) > 0 || len(report.DataConflicts) > 0) && !opts.Force {
+		var held []string
+		if n := len(report.Conflicts); n > 0 {
+			held = append(held, fmt.Sprintf("tasks for %d of the migrated days", n))
+		}
+		if len(report.DataConflicts) > 0 {
+			held = append(held, "its own "+strings.Join(report.DataConflicts, ", "))
+		}
+		return report, fmt.Errorf("destination already has %s", strings.Join(held, " and "))
+	}
+
+	for _, day := range c.days {
+		if err := dst.SaveTasks(day.Date, day.Tasks); err != nil {
+			return report, fmt.Errorf("write %s: %w", dayKey(day.Date), err)
+		}
+	}
+	if err := dst.SaveScripts(c.scripts); err != nil {
+		return report, fmt.Errorf("write scripts: %w", err)
+	}
+	if err := dst.SaveRecurrences(c.recs); err != nil {
+		return report, fmt.Errorf("write recurrences: %w", err)
+	}
+	if err := dst.SaveFilters(c.filters); err != nil {
+		return report, fmt.Errorf("write filters: %w", err)
+	}
+	if err := dst.SaveTrash(c.trash); err != nil {
+		return report, fmt.Errorf("write trash: %w", err)
+	}
+
+	return report, verify(dst, c, report.Checksum)
+}
+
+func snapshot(s Store) (contents, error) {
+	s = readOnly(s)
+	var c contents
+	var err error
+	if c.days, err = storedDays(s, Query{}); err != nil {
+		return c, fmt.Errorf("read tasks: %w", err)
+	}
+	return c, readRest(s, &c)
+}
+
+// readRest reads everything but the days into c.
+func readRest(s Store, c *contents) error {
+	var err error
+	if c.scripts, err = s.LoadScripts(); err != nil {
+		return fmt.Errorf("read scripts: %w", err)
+	}
+	if c.recs, err = s.LoadRecurrences(); err != nil {
+		return fmt.Errorf("read recurrences: %w", err)
+	}
+	if c.filters, err = s.LoadFilters(); err != nil {
+		return fmt.Errorf("read filters: %w", err)
+	}
+	if c.trash, err = s.LoadTrash(); err != nil {
+		return fmt.Errorf("read trash: %w", err)
+	}
+	return nil
+}
+
+// dataConflicts names what have holds, besides days, that copying c over
+// it would lose: anything that is neither empty, nor the same as c, nor
+// the defaults of a fresh store.
+func dataConflicts(have, c contents) []string {
+	var out []string
+	if !replaceable(have.scripts, c.scripts, DefaultScripts()) {
+		out = append(out, "scripts")
+	}
+	if !replaceable(have.recs, c.recs, nil) {
+		out = append(out, "recurring tasks")
+	}
+	if !replaceable(have.filters, c.filters, DefaultFilters()) {
+		out = append(out, "filters")
+	}
+	if !replaceable(have.trash, c.trash, nil) {
+		out = append(out, "trash")
+	}
+	return out
+}
+
+func replaceable[T any](have, incoming, defaults []T) bool {
+	return len(have) == 0 || sameEncoding(have, incoming) || sameEncoding(have, defaults)
+}
+
+func sameEncoding(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// storedDays returns the tasks stored in the days q covers, grouped by day
+// in date order. Recurring instances that were never saved are left out, as
+// are days without tasks, which not every backend keeps.
+func storedDays(s Store, q Query) ([]DayTasks, error) {
+	tasks, err := s.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Date.Before(tasks[j].Date) })
+	var days []DayTasks
+	for _, dt := range tasks {
+		if n := len(days); n == 0 || !days[n-1].Date.Equal(dt.Date) {
+			days = append(days, DayTasks{Date: dt.Date})
+		}
+		days[len(days)-1].Tasks = append(days[len(days)-1].Tasks, dt.Task)
+	}
+	return days, nil
+}
+
+// verify re-reads the migrated data from dst and compares it to the source.
+func verify(dst Store, src contents, want string) error {
+	var c contents
+	for _, day := range src.days {
+		days, err := storedDays(dst, Query{From: day.Date, To: day.Date})
+		if err != nil {
+			return fmt.Errorf("verify %s: %w", dayKey(day.Date), err)
+		}
+		var tasks []model.Task
+		if len(days) > 0 {
+			tasks = days[0].Tasks
+		}
+		if len(tasks) != len(day.Tasks) {
+			return fmt.Errorf("verify %s: expected %d tasks, found %d", dayKey(day.Date), len(day.Tasks), len(tasks))
+		}
+		c.days = append(c.days, DayTasks{Date: day.Date, Tasks: tasks})
+	}
+	var err error
+	if c.scripts, err = dst.LoadScripts(); err != nil {
+		return fmt.Errorf("verify scripts: %w", err)
+	}
+	if len(c.scripts) != len(src.scripts) {
+		return fmt.Errorf("verify scripts: expected %d, found %d", len(src.scripts), len(c.scripts))
+	}
+	if c.recs, err = dst.LoadRecurrences(); err != nil {
+		return fmt.Errorf("verify recurrences: %w", err)
+	}
+	if len(c.recs) != len(src.recs) {
+		return fmt.Errorf("verify recurrences: expected %d, found %d", len(src.recs), len(c.recs))
+	}
+	if c.filters, err = dst.LoadFilters(); err != nil {
+		return fmt.Errorf("verify filters: %w", err)
+	}
+	if len(c.filters) != len(src.filters) {
+		return fmt.Errorf("verify filters: expected %d, found %d", len(src.filters), len(c.filters))
+	}
+	if c.trash, err = dst.LoadTrash(); err != nil {
+		return fmt.Errorf("verify trash: %w", err)
+	}
+	if len(c.trash) != len(src.trash) {
+		return fmt.Errorf("verify trash: expected %d, found %d", len(src.trash), len(c.trash))
+	}
+
+	got, err := checksum(c)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("verify: checksum mismatch (source %s, destination %s)", want, got)
+	}
+	return nil
+}
+
+// checksum hashes the canonical JSON encoding of everything in c, so it is
+// independent of the on-disk layout.
+func checksum(c contents) (string, error) {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, day := range c.days {
+		if err := enc.Encode(dayKey(day.Date)); err != nil {
+			return "", err
+		}
+		if err := enc.Encode(day.Tasks); err != nil {
+			return "", err
+		}
+	}
+	scripts := c.scripts
+	if len(scripts) == 0 {
+		scripts = []model.Script{}
+	}
+	if err := enc.Encode(scripts); err != nil {
+		return "", err
+	}
+	recs := c.recs
+	if len(recs) == 0 {
+		recs = []model.Recurrence{}
+	}
+	if err := enc.Encode(recs); err != nil {
+		return "", err
+	}
+	filters := c.filters
+	if len(filters) == 0 {
+		filters = []model.Filter{}
+	}
+	if err := enc.Encode(filters); err != nil {
+		return "", err
+	}
+	trash := c.trash
+	if len(trash) == 0 {
+		trash = []model.TrashItem{}
+	}
+	if err := enc.Encode(trash); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
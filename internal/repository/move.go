@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"zenith/internal/model"
+)
+
+// MoveTask reschedules the task with the given ID from day from to day to,
+// saving both days together. With keepOriginal set the task is copied
+// instead and the copy gets a new ID. A recurring instance becomes a one-off
+// task on its new day; when it is moved its old day is recorded as an
+// exception so the rule does not bring it back.
+func MoveTask(s Store, from time.Time, id string, to time.Time, keepOriginal bool) (model.Task, error) {
+	from, to = Day(from), Day(to)
+	if from.Equal(to) && !keepOriginal {
+		return model.Task{}, fmt.Errorf("task is already on %s", dayKey(to))
+	}
+
+	source, err := s.LoadTasks(from)
+	if err != nil {
+		return model.Task{}, err
+	}
+	idx := findTask(source, id)
+	if idx < 0 {
+		return model.Task{}, fmt.Errorf("no task %s on %s", id, dayKey(from))
+	}
+
+	t := source[idx]
+	recID := t.RecurrenceID
+	t.RecurrenceID = ""
+	t.CarriedTo = ""
+	if keepOriginal {
+		t.ID = model.NewID()
+	} else {
+		source = append(source[:idx], source[idx+1:]...)
+	}
+
+	if from.Equal(to) {
+		source = append(source, t)
+		return t, s.SaveTasks(from, source)
+	}
+
+	target, err := s.LoadTasks(to)
+	if err != nil {
+		return model.Task{}, err
+	}
+	target = append(target, t)
+
+	// Record the exception first and take it back if the days cannot be
+	// saved, so a failure never leaves the instance on both days.
+	var recs []model.Recurrence
+	if !keepOriginal && recID != "" {
+		if recs, err = s.LoadRecurrences(); err != nil {
+			return model.Task{}, err
+		}
+		if err := addException(s, recID, from); err != nil {
+			return model.Task{}, err
+		}
+	}
+	if err := s.SaveDays([]DayTasks{{Date: from, Tasks: source}, {Date: to, Tasks: target}}); err != nil {
+		if recs != nil {
+			err = errors.Join(err, s.SaveRecurrences(recs))
+		}
+		return model.Task{}, err
+	}
+	return t, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"zenith/internal/model"
+)
+
+func TestMoveTask(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		mustSave(t, s, "2026-03-04", model.Task{ID: "a", Title: "report", CarriedTo: "2026-03-05"}, model.Task{ID: "b", Title: "groceries"})
+		mustSave(t, s, "2026-03-06", model.Task{ID: "c", Title: "call mum"})
+
+		moved, err := MoveTask(s, date("2026-03-04"), "a", date("2026-03-06"), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if moved.ID != "a" || moved.CarriedTo != "" {
+			t.Errorf("moved task = %+v, want ID a and no CarriedTo", moved)
+		}
+		all, _ := s.Query(Query{})
+		want := []string{"2026-03-04 groceries", "2026-03-06 call mum", "2026-03-06 report"}
+		if got := titlesOf(all); !slices.Equal(got, want) {
+			t.Errorf("after the move the store holds %q, want %q", got, want)
+		}
+
+		copied, err := MoveTask(s, date("2026-03-04"), "b", date("2026-03-06"), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if copied.ID == "b" || copied.ID == "" {
+			t.Errorf("the copy has ID %q, want a new one", copied.ID)
+		}
+		all, _ = s.Query(Query{})
+		want = []string{"2026-03-04 groceries", "2026-03-06 call mum", "2026-03-06 report", "2026-03-06 groceries"}
+		if got := titlesOf(all); !slices.Equal(got, want) {
+			t.Errorf("after the copy the store holds %q, want %q", got, want)
+		}
+
+		if _, err := MoveTask(s, date("2026-03-06"), "c", date("2026-03-06"), false); err == nil {
+			t.Error("moving a task onto its own day succeeded")
+		}
+		dup, err := MoveTask(s, date("2026-03-06"), "c", date("2026-03-06"), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks, _ := s.LoadTasks(date("2026-03-06"))
+		if len(tasks) != 4 || tasks[3].ID != dup.ID || tasks[3].Title != "call mum" || dup.ID == "c" {
+			t.Errorf("after copying within the day it holds %+v", tasks)
+		}
+
+		if _, err := MoveTask(s, date("2026-03-04"), "missing", date("2026-03-06"), false); err == nil {
+			t.Error("moving a task that is not there succeeded")
+		}
+	})
+}
+
+func TestMoveRecurringInstance(t *testing.T) {
+	rec := model.Recurrence{ID: "r", Rule: "daily", Start: "2026-03-01", Title: "water plants"}
+	instance := func(t *testing.T, s Store, day string) string {
+		t.Helper()
+		tasks, err := s.LoadTasks(date(day))
+		if err != nil || len(tasks) != 1 {
+			t.Fatalf("LoadTasks(%s) = %v, %v; want the instance", day, tasks, err)
+		}
+		return tasks[0].ID
+	}
+
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := s.SaveRecurrences([]model.Recurrence{rec}); err != nil {
+			t.Fatal(err)
+		}
+
+		copied, err := MoveTask(s, date("2026-03-04"), instance(t, s, "2026-03-04"), date("2026-03-10"), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if copied.RecurrenceID != "" {
+			t.Errorf("the copy still belongs to the rule: %+v", copied)
+		}
+		instance(t, s, "2026-03-04") // copying leaves the instance alone
+
+		moved, err := MoveTask(s, date("2026-03-05"), instance(t, s, "2026-03-05"), date("2026-03-10"), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if moved.RecurrenceID != "" {
+			t.Errorf("the moved task still belongs to the rule: %+v", moved)
+		}
+		if tasks, _ := s.LoadTasks(date("2026-03-05")); len(tasks) != 0 {
+			t.Errorf("the rule brought the moved instance back: %+v", tasks)
+		}
+		recs, _ := s.LoadRecurrences()
+		if len(recs) != 1 || !slices.Equal(recs[0].Exceptions, []string{"2026-03-05"}) {
+			t.Errorf("recurrences after the move = %+v, want an exception on 2026-03-05", recs)
+		}
+		tasks, _ := s.LoadTasks(date("2026-03-10"))
+		var titles []string
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		// The rule's own instance comes first, then the copy and the move.
+		if want := []string{"water plants", "water plants", "water plants"}; !slices.Equal(titles, want) {
+			t.Errorf("2026-03-10 holds %q, want %q", titles, want)
+		}
+	})
+}
+
+func TestMoveTaskFailure(t *testing.T) {
+	rec := model.Recurrence{ID: "r", Rule: "daily", Start: "2026-03-01", Title: "water plants"}
+	tests := []struct {
+		name  string
+		store func(Store) Store
+	}{
+		{"days", func(s Store) Store { return failing{Store: s, days: map[string]bool{"2026-03-10": true}} }},
+		{"exception", func(s Store) Store { return failing{Store: s, recs: true} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := NewMemoryStore()
+			if err := mem.SaveRecurrences([]model.Recurrence{rec}); err != nil {
+				t.Fatal(err)
+			}
+			tasks, _ := mem.LoadTasks(date("2026-03-05"))
+			s := tt.store(mem)
+
+			if _, err := MoveTask(s, date("2026-03-05"), tasks[0].ID, date("2026-03-10"), false); !errors.Is(err, errWrite) {
+				t.Fatalf("MoveTask = %v, want the write error", err)
+			}
+			// Neither half of the move stays: the instance is only on its
+			// old day and the rule has no exception for it.
+			if got, _ := mem.LoadTasks(date("2026-03-05")); len(got) != 1 || got[0].RecurrenceID != "r" {
+				t.Errorf("2026-03-05 holds %+v, want the instance", got)
+			}
+			if got, _ := mem.LoadTasks(date("2026-03-10")); len(got) != 1 || got[0].RecurrenceID != "r" {
+				t.Errorf("2026-03-10 holds %+v, want only the rule's own instance", got)
+			}
+			if recs, _ := mem.LoadRecurrences(); len(recs[0].Exceptions) != 0 {
+				t.Errorf("exceptions after the failed move = %q, want none", recs[0].Exceptions)
+			}
+		})
+	}
+}
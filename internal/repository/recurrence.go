@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"time"
+	"zenith/internal/model"
+	"zenith/internal/recur"
+
+	"github.com/google/uuid"
+)
+
+// materialize appends the instances of recs that fall on day d and are not
+// already stored there. Instance IDs are derived from the recurrence and
+// the day, so an unsaved instance keeps its ID across loads.
+func materialize(d time.Time, tasks []model.Task, recs []model.Recurrence) []model.Task {
+	day := dayKey(d)
+	stored := make(map[string]bool)
+	for _, t := range tasks {
+		if t.RecurrenceID != "" {
+			stored[t.RecurrenceID] = true
+		}
+	}
+	for _, r := range recs {
+		if stored[r.ID] || r.Skips(day) {
+			continue
+		}
+		// Rules are validated when saved; skip anything unreadable.
+		rule, err := recur.Parse(r.Rule)
+		if err != nil {
+			continue
+		}
+		start, err := parseDayKey(r.Start)
+		if err != nil || !rule.Occurs(start, d) {
+			continue
+		}
+		id := uuid.NewSHA1(idNamespace, []byte("recurrence/"+r.ID+"/"+day)).String()
+		tasks = append(tasks, r.Instance(id, d))
+	}
+	return tasks
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+	"time"
+	"zenith/internal/model"
+)
+
+func date(s string) time.Time {
+	d, err := parseDayKey(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestMaterialize(t *testing.T) {
+	recs := []model.Recurrence{
+		{ID: "standup", Rule: "weekdays", Start: "2026-03-02", Title: "standup", Tags: []string{"work"}},
+		{ID: "rent", Rule: "monthly on 31", Start: "2026-01-31", Title: "rent"},
+		{ID: "gym", Rule: "every 2 weeks on mon", Start: "2026-03-02", Title: "gym", Exceptions: []string{"2026-03-16"}},
+		{ID: "broken", Rule: "sometimes", Start: "2026-03-02", Title: "broken"},
+		{ID: "undated", Rule: "daily", Start: "soon", Title: "undated"},
+	}
+	stored := model.Task{ID: "kept", Title: "standup, edited", RecurrenceID: "standup"}
+
+	tests := []struct {
+		day    string
+		stored []model.Task
+		want   []string
+	}{
+		{"2026-03-01", nil, nil},                                                 // before any start
+		{"2026-03-02", nil, []string{"standup", "gym"}},                          // start day
+		{"2026-03-09", nil, []string{"standup"}},                                 // off week for gym
+		{"2026-03-14", nil, nil},                                                 // a Saturday
+		{"2026-03-16", nil, []string{"standup"}},                                 // gym's instance was deleted
+		{"2026-03-31", nil, []string{"standup", "rent"}},                         // rent on the 31st
+		{"2026-04-30", nil, []string{"standup", "rent"}},                         // clamped to April's last day
+		{"2026-03-30", []model.Task{stored}, []string{"standup, edited", "gym"}}, // saved instance wins
+	}
+	for _, tt := range tests {
+		d := date(tt.day)
+		tasks := materialize(d, slices.Clone(tt.stored), recs)
+		var got []string
+		for _, task := range tasks {
+			got = append(got, task.Title)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("materialize on %s = %q, want %q", tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestMaterializeInstanceIDs(t *testing.T) {
+	recs := []model.Recurrence{{ID: "r", Rule: "daily", Start: "2026-03-01", Title: "water plants", Tags: []string{"home"}}}
+	d := date("2026-03-05")
+
+	first := materialize(d, nil, recs)
+	again := materialize(d, nil, recs)
+	next := materialize(d.AddDate(0, 0, 1), nil, recs)
+	if len(first) != 1 || len(again) != 1 || len(next) != 1 {
+		t.Fatalf("expected one instance per day, got %d, %d and %d", len(first), len(again), len(next))
+	}
+	if first[0].ID == "" || first[0].ID != again[0].ID {
+		t.Errorf("instance ID is not stable across loads: %q, %q", first[0].ID, again[0].ID)
+	}
+	if first[0].ID == next[0].ID {
+		t.Errorf("instances on different days share the ID %q", first[0].ID)
+	}
+	if first[0].RecurrenceID != "r" || !first[0].CreatedAt.Equal(d) {
+		t.Errorf("instance = %+v, want RecurrenceID r created on %s", first[0], dayKey(d))
+	}
+
+	first[0].Tags[0] = "changed"
+	if recs[0].Tags[0] != "home" {
+		t.Error("editing an instance's tags changed the recurrence")
+	}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"zenith/internal/model"
+)
+
+// Unfinished returns the incomplete tasks stored on days before day that
+// have not already been carried over. Recurring instances are left alone,
+// their rule already schedules the next one.
+func Unfinished(s Store, day time.Time) ([]DatedTask, error) {
+	return s.Query(Query{
+		To: Day(day).AddDate(0, 0, -1),
+		Match: func(dt DatedTask) bool {
+			return !dt.Task.Completed && dt.Task.CarriedTo == "" && dt.Task.RecurrenceID == ""
+		},
+	})
+}
+
+// Rollover carries every unfinished task from past days onto day and
+// returns how many were carried. Moved tasks keep their ID and leave their
+// old day; with keepOriginals set the original stays behind, marked as
+// carried, and the copy gets a new ID.
+func Rollover(s Store, day time.Time, keepOriginals bool) (int, error) {
+	stale, err := Unfinished(s, day)
+	if len(stale) == 0 {
+		return 0, err
+	}
+	// Unreadable days are skipped; the error is reported once the rest is carried.
+	scanErr := err
+
+	target, err := s.LoadTasks(day)
+	if err != nil {
+		return 0, err
+	}
+
+	carried := make(map[string]map[string]bool) // origin day -> task IDs
+	for _, dt := range stale {
+		t := dt.Task
+		if t.CarriedFrom == "" {
+			t.CarriedFrom = dayKey(dt.Date)
+		}
+		t.CarryCount++
+		if keepOriginals {
+			t.ID = model.NewID()
+		}
+		target = append(target, t)
+
+		key := dayKey(dt.Date)
+		if carried[key] == nil {
+			carried[key] = make(map[string]bool)
+		}
+		carried[key][dt.Task.ID] = true
+	}
+
+	// Write the target first: a failure part way through leaves a task on
+	// both days rather than on neither.
+	if err := s.SaveTasks(day, target); err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for key, ids := range carried {
+		origin, _ := parseDayKey(key)
+		tasks, err := s.LoadTasks(origin)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		kept := tasks[:0]
+		for _, t := range tasks {
+			switch {
+			case !ids[t.ID]:
+				kept = append(kept, t)
+			case keepOriginals:
+				t.CarriedTo = dayKey(day)
+				kept = append(kept, t)
+			}
+		}
+		if err := s.SaveTasks(origin, kept); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(stale), errors.Join(append(errs, scanErr)...)
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+	"zenith/internal/model"
+)
+
+// stale fills s with two past days of work, some of it finished, and a
+// recurring rule.
+func stale(t *testing.T, s Store) {
+	t.Helper()
+	done := time.Date(2026, time.March, 2, 17, 0, 0, 0, time.Local)
+	mustSave(t, s, "2026-03-02",
+		model.Task{ID: "a", Title: "report"},
+		model.Task{ID: "b", Title: "done", Completed: true, CompletedAt: &done},
+	)
+	mustSave(t, s, "2026-03-03", model.Task{ID: "c", Title: "invoice", CarriedFrom: "2026-02-27", CarryCount: 2})
+	mustSave(t, s, "2026-03-04", model.Task{ID: "d", Title: "today"})
+	if err := s.SaveRecurrences([]model.Recurrence{{ID: "r", Rule: "FREQ=WEEKLY;BYDAY=MO", Start: "2026-03-02", Title: "plan week"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRolloverMove(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		stale(t, s)
+		n, err := Rollover(s, date("2026-03-04"), false)
+		if err != nil || n != 2 {
+			t.Fatalf("Rollover = %d, %v; want 2", n, err)
+		}
+
+		// Finished tasks and the rule's instance stay where they are.
+		all, _ := s.Query(Query{To: date("2026-03-04")})
+		want := []string{"2026-03-02 done", "2026-03-02 plan week", "2026-03-04 today", "2026-03-04 report", "2026-03-04 invoice"}
+		if got := titlesOf(all); !slices.Equal(got, want) {
+			t.Errorf("after rolling over the store holds %q, want %q", got, want)
+		}
+		tasks, _ := s.LoadTasks(date("2026-03-04"))
+		report, invoice := tasks[1], tasks[2]
+		if report.ID != "a" || report.CarriedFrom != "2026-03-02" || report.CarryCount != 1 || report.CarriedTo != "" {
+			t.Errorf("carried report = %+v, want ID a from 2026-03-02 carried once", report)
+		}
+		// A task carried before keeps the day it first came from.
+		if invoice.ID != "c" || invoice.CarriedFrom != "2026-02-27" || invoice.CarryCount != 3 {
+			t.Errorf("carried invoice = %+v, want ID c from 2026-02-27 carried three times", invoice)
+		}
+
+		if n, err := Rollover(s, date("2026-03-04"), false); err != nil || n != 0 {
+			t.Errorf("a second Rollover = %d, %v; want nothing left to carry", n, err)
+		}
+	})
+}
+
+func TestRolloverCopy(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		stale(t, s)
+		n, err := Rollover(s, date("2026-03-04"), true)
+		if err != nil || n != 2 {
+			t.Fatalf("Rollover = %d, %v; want 2", n, err)
+		}
+
+		for _, origin := range []struct{ day, id string }{{"2026-03-02", "a"}, {"2026-03-03", "c"}} {
+			tasks, _ := s.LoadTasks(date(origin.day))
+			i := findTask(tasks, origin.id)
+			if i < 0 || tasks[i].CarriedTo != "2026-03-04" {
+				t.Errorf("%s holds %+v, want %s marked as carried to 2026-03-04", origin.day, tasks, origin.id)
+			}
+		}
+		tasks, _ := s.LoadTasks(date("2026-03-04"))
+		if len(tasks) != 3 {
+			t.Fatalf("2026-03-04 holds %+v, want today and two copies", tasks)
+		}
+		for _, copy := range tasks[1:] {
+			if copy.ID == "a" || copy.ID == "c" || copy.CarriedTo != "" || copy.CarryCount == 0 {
+				t.Errorf("copy = %+v, want a new ID and a carry count", copy)
+			}
+		}
+
+		// The originals are marked, so they are not carried again.
+		if n, err := Rollover(s, date("2026-03-05"), true); err != nil || n != 3 {
+			t.Errorf("Rollover the next day = %d, %v; want only the three tasks of 2026-03-04", n, err)
+		}
+	})
+}
+
+func TestRolloverFailure(t *testing.T) {
+	t.Run("target", func(t *testing.T) {
+		mem := NewMemoryStore()
+		stale(t, mem)
+		s := failing{Store: mem, days: map[string]bool{"2026-03-04": true}}
+		if n, err := Rollover(s, date("2026-03-04"), false); !errors.Is(err, errWrite) || n != 0 {
+			t.Errorf("Rollover = %d, %v; want 0 and the write error", n, err)
+		}
+		all, _ := mem.Query(Query{To: date("2026-03-04")})
+		want := []string{"2026-03-02 report", "2026-03-02 done", "2026-03-03 invoice", "2026-03-04 today"}
+		if got := titlesOf(all); !slices.Equal(got, want) {
+			t.Errorf("after the failure the store holds %q, want %q", got, want)
+		}
+	})
+
+	t.Run("origin", func(t *testing.T) {
+		mem := NewMemoryStore()
+		stale(t, mem)
+		s := failing{Store: mem, days: map[string]bool{"2026-03-02": true}}
+		n, err := Rollover(s, date("2026-03-04"), false)
+		if !errors.Is(err, errWrite) || n != 2 {
+			t.Errorf("Rollover = %d, %v; want 2 and the write error", n, err)
+		}
+		// The day that could not be saved keeps its task, so it is on two
+		// days rather than lost; the other origin was cleared.
+		all, _ := mem.Query(Query{To: date("2026-03-04")})
+		want := []string{"2026-03-02 report", "2026-03-02 done", "2026-03-04 today", "2026-03-04 report", "2026-03-04 invoice"}
+		if got := titlesOf(all); !slices.Equal(got, want) {
+			t.Errorf("after the failure the store holds %q, want %q", got, want)
+		}
+	})
+}
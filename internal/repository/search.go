@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"zenith/internal/model"
+)
+
+// searcher is implemented by stores that can scan every stored task faster
+// than a full Query.
+type searcher interface {
+	search(match func(DatedTask) bool) ([]DatedTask, error)
+}
+
+// Search returns the stored tasks whose title, notes, tags or subtasks
+// contain text, ignoring case, newest day first.
+func Search(s Store, text string) ([]DatedTask, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return nil, nil
+	}
+	return Select(s, func(dt DatedTask) bool { return containsText(dt.Task, text) })
+}
+
+// Select returns the stored tasks accepted by match, newest day first.
+func Select(s Store, match func(DatedTask) bool) ([]DatedTask, error) {
+	var out []DatedTask
+	var err error
+	if fast, ok := s.(searcher); ok {
+		out, err = fast.search(match)
+	} else {
+		out, err = s.Query(Query{Match: match})
+	}
+	newestFirst(out)
+	return out, err
+}
+
+func newestFirst(tasks []DatedTask) {
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Date.After(tasks[j].Date) })
+}
+
+// containsText reports whether any searchable field of t contains text,
+// which must already be lower case.
+func containsText(t model.Task, text string) bool {
+	if strings.Contains(strings.ToLower(t.Title), text) || strings.Contains(strings.ToLower(t.Notes), text) {
+		return true
+	}
+	for _, tag := range t.Tags {
+		if strings.Contains(tag, text) {
+			return true
+		}
+	}
+	for _, s := range t.Subtasks {
+		if strings.Contains(strings.ToLower(s.Title), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchIndex caches the tasks of every day file of a JSONStore, together
+// with the size and modification time the file had when it was read. It is
+// saved as search_index.json so that searching years of files only re-reads
+// the days that changed since the last search.
+type searchIndex struct {
+	mu    sync.Mutex
+	days  map[string]indexedDay // by file name
+	dirty bool
+}
+
+type indexedDay struct {
+	ModTime int64        `json:"mod_time"` // UnixNano
+	Size    int64        `json:"size"`
+	Tasks   []model.Task `json:"tasks"`
+}
+
+func (s *JSONStore) indexFilename() string {
+	return filepath.Join(s.cfg.DataDir, "search_index.json")
+}
+
+// search refreshes the index against the day files on disk, then scans it.
+// A missing or unreadable index file is rebuilt from scratch.
+func (s *JSONStore) search(match func(DatedTask) bool) ([]DatedTask, error) {
+	idx := &s.index
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.days == nil {
+		idx.days = make(map[string]indexedDay)
+		if data, err := os.ReadFile(s.indexFilename()); err == nil {
+			if json.Unmarshal(data, &idx.days) != nil {
+				idx.days = make(map[string]indexedDay)
+			}
+		}
+	}
+
+	dates, err := s.Dates()
+	if err != nil {
+		return nil, err
+	}
+	var out []DatedTask
+	var errs []error
+	seen := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		path := s.filename(d)
+		name := filepath.Base(path)
+		seen[name] = true
+
+		info, err := os.Stat(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		day, ok := idx.days[name]
+		if !ok || day.ModTime != info.ModTime().UnixNano() || day.Size != info.Size() {
+			tasks, err := s.loadStored(d)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			day = indexedDay{ModTime: info.ModTime().UnixNano(), Size: info.Size(), Tasks: tasks}
+			idx.days[name] = day
+			idx.dirty = true
+		}
+		for _, t := range day.Tasks {
+			if dt := (DatedTask{Date: d, Task: t}); match(dt) {
+				out = append(out, dt)
+			}
+		}
+	}
+	for name := range idx.days {
+		if !seen[name] {
+			delete(idx.days, name)
+			idx.dirty = true
+		}
+	}
+
+	if idx.dirty {
+		data, err := json.Marshal(idx.days)
+		if err == nil {
+			err = writeFileAtomic(s.indexFilename(), data, 0644, false)
+		}
+		// The index is only a cache; a failed write costs speed, not data.
+		idx.dirty = err != nil
+	}
+	return out, errors.Join(errs...)
+}
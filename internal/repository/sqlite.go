@@ -0,0 +1,434 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"zenith/internal/model"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order; PRAGMA user_version records how many
+// have run. Append new steps, never edit released ones.
+//
+// The tasks table keeps the full task as JSON in data so that new task
+// fields do not need a migration; the other columns exist for querying.
+var migrations = []string{
+	`CREATE TABLE tasks (
+		day        TEXT    NOT NULL,
+		position   INTEGER NOT NULL,
+		title      TEXT    NOT NULL,
+		completed  INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT    NOT NULL,
+		data       TEXT    NOT NULL,
+		PRIMARY KEY (day, position)
+	);
+	CREATE TABLE scripts (
+		position    INTEGER PRIMARY KEY,
+		name        TEXT NOT NULL,
+		command     TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT ''
+	);
+	INSERT INTO scripts (position, name, command, description)
+		VALUES (0, 'hello-world', 'echo ''hello world''', 'prints hello world');`,
+
+	`CREATE TABLE recurrences (
+		position INTEGER PRIMARY KEY,
+		data     TEXT NOT NULL
+	);`,
+
+	`CREATE TABLE filters (
+		position INTEGER PRIMARY KEY,
+		name     TEXT NOT NULL,
+		query    TEXT NOT NULL
+	);
+	INSERT INTO filters (position, name, query) VALUES
+		(0, 'Overdue', 'is:overdue'),
+		(1, 'This week', 'date:this-week'),
+		(2, 'Work, high priority', 'tag:work priority:>=high status:open');`,
+
+	`CREATE TABLE trash (
+		position INTEGER PRIMARY KEY,
+		data     TEXT NOT NULL
+	);`,
+}
+
+// SQLiteStore keeps everything in a single SQLite database. It uses a
+// pure-Go driver, so no cgo toolchain is needed.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// A single connection serialises writers and keeps the pragmas below
+	// in effect for every statement.
+	db.SetMaxOpenConns(1)
+	for _, pragma := range []string{
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = FULL",
+		"PRAGMA busy_timeout = 5000",
+	} {
+		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("%s: %w", pragma, err)
+		}
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d)", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) LoadTasks(d time.Time) ([]model.Task, error) {
+	rows, err := s.db.Query(`SELECT data FROM tasks WHERE day = ? ORDER BY position`, dayKey(d))
+	if err != nil {
+		return []model.Task{}, err
+	}
+	defer rows.Close()
+
+	tasks := []model.Task{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return []model.Task{}, err
+		}
+		var t model.Task
+		if err := json.Unmarshal([]byte(data), &t); err != nil {
+			return []model.Task{}, fmt.Errorf("corrupt task on %s: %w", dayKey(d), err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return []model.Task{}, err
+	}
+	backfillIDs(d, tasks)
+
+	recs, err := s.LoadRecurrences()
+	if err != nil {
+		return tasks, err
+	}
+	return materialize(d, tasks, recs), nil
+}
+
+func (s *SQLiteStore) SaveTasks(d time.Time, tasks []model.Task) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op after commit
+
+	if err := saveTasksTx(tx, dayKey(d), tasks); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) SaveDays(days []DayTasks) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op after commit
+
+	for _, day := range days {
+		if err := saveTasksTx(tx, dayKey(day.Date), day.Tasks); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func saveTasksTx(tx *sql.Tx, day string, tasks []model.Task) error {
+	if _, err := tx.Exec(`DELETE FROM tasks WHERE day = ?`, day); err != nil {
+		return err
+	}
+	for i, t := range tasks {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`INSERT INTO tasks (day, position, title, completed, created_at, data) VALUES (?, ?, ?, ?, ?, ?)`,
+			day, i, t.Title, t.Completed, t.CreatedAt.Format(time.RFC3339Nano), string(data),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) LoadScripts() ([]model.Script, error) {
+	rows, err := s.db.Query(`SELECT name, command, description FROM scripts ORDER BY position`)
+	if err != nil {
+		return []model.Script{}, err
+	}
+	defer rows.Close()
+
+	scripts := []model.Script{}
+	for rows.Next() {
+		var sc model.Script
+		if err := rows.Scan(&sc.Name, &sc.Command, &sc.Description); err != nil {
+			return []model.Script{}, err
+		}
+		scripts = append(scripts, sc)
+	}
+	return scripts, rows.Err()
+}
+
+func (s *SQLiteStore) SaveScripts(scripts []model.Script) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM scripts`); err != nil {
+		return err
+	}
+	for i, sc := range scripts {
+		_, err := tx.Exec(
+			`INSERT INTO scripts (position, name, command, description) VALUES (?, ?, ?, ?)`,
+			i, sc.Name, sc.Command, sc.Description,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) LoadRecurrences() ([]model.Recurrence, error) {
+	rows, err := s.db.Query(`SELECT data FROM recurrences ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recs []model.Recurrence
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var r model.Recurrence
+		if err := json.Unmarshal([]byte(data), &r); err != nil {
+			return nil, fmt.Errorf("corrupt recurrence: %w", err)
+		}
+		recs = append(recs, r)
+	}
+	return recs, rows.Err()
+}
+
+func (s *SQLiteStore) SaveRecurrences(recs []model.Recurrence) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM recurrences`); err != nil {
+		return err
+	}
+	for i, r := range recs {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO recurrences (position, data) VALUES (?, ?)`, i, string(data)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) LoadFilters() ([]model.Filter, error) {
+	rows, err := s.db.Query(`SELECT name, query FROM filters ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var filters []model.Filter
+	for rows.Next() {
+		var f model.Filter
+		if err := rows.Scan(&f.Name, &f.Query); err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, rows.Err()
+}
+
+func (s *SQLiteStore) SaveFilters(filters []model.Filter) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM filters`); err != nil {
+		return err
+	}
+	for i, f := range filters {
+		if _, err := tx.Exec(`INSERT INTO filters (position, name, query) VALUES (?, ?, ?)`, i, f.Name, f.Query); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) LoadTrash() ([]model.TrashItem, error) {
+	rows, err := s.db.Query(`SELECT data FROM trash ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.TrashItem
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var item model.TrashItem
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			return nil, fmt.Errorf("corrupt trash item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (s *SQLiteStore) SaveTrash(items []model.TrashItem) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM trash`); err != nil {
+		return err
+	}
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO trash (position, data) VALUES (?, ?)`, i, string(data)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Dates() ([]time.Time, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT day FROM tasks ORDER BY day`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		d, err := parseDayKey(key)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, d)
+	}
+	return dates, rows.Err()
+}
+
+func (s *SQLiteStore) Query(q Query) ([]DatedTask, error) {
+	// Days are stored as YYYY-MM-DD, so string comparison orders them.
+	from, to := "0000-00-00", "9999-99-99"
+	if !q.From.IsZero() {
+		from = dayKey(q.From)
+	}
+	if !q.To.IsZero() {
+		to = dayKey(q.To)
+	}
+	rows, err := s.db.Query(
+		`SELECT day, position, data FROM tasks WHERE day >= ? AND day <= ? ORDER BY day, position`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []DatedTask
+	for rows.Next() {
+		var key, data string
+		var position int
+		if err := rows.Scan(&key, &position, &data); err != nil {
+			return nil, err
+		}
+		d, err := parseDayKey(key)
+		if err != nil {
+			return nil, err
+		}
+		var t model.Task
+		if err := json.Unmarshal([]byte(data), &t); err != nil {
+			return nil, fmt.Errorf("corrupt task on %s: %w", key, err)
+		}
+		if t.ID == "" {
+			t.ID = legacyID(d, position, t)
+		}
+		dt := DatedTask{Date: d, Task: t}
+		if q.matches(dt) {
+			out = append(out, dt)
+		}
+	}
+	return out, rows.Err()
+}
@@ -2,57 +2,250 @@ package repository
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 	"zenith/internal/config"
 	"zenith/internal/model"
 )
 
-func EnsureDir() {
-	if _, err := os.Stat(config.PersistenceDir); os.IsNotExist(err) {
-		_ = os.MkdirAll(config.PersistenceDir, 0755)
-	}
+// JSONStore keeps one tasks_YYYY-MM-DD.json file per day, a scripts.json, a
+// recurrences.json, a filters.json and a trash.json in the data directory.
+type JSONStore struct {
+	cfg   config.Config
+	index searchIndex
+
+	// inspecting leaves corrupt files where they are, see inspect
+	inspecting bool
+}
+
+func NewJSONStore(cfg config.Config) *JSONStore {
+	return &JSONStore{cfg: cfg}
+}
+
+func (s *JSONStore) Close() error { return nil }
+
+// inspect returns a store over the same directory that reports corrupt
+// files without moving them aside, so reading it changes nothing.
+func (s *JSONStore) inspect() Store {
+	return &JSONStore{cfg: s.cfg, inspecting: true}
 }
 
-func GetFilename(d time.Time) string {
-	return filepath.Join(config.PersistenceDir, fmt.Sprintf("tasks_%s.json", d.Format("2006-01-02")))
+func (s *JSONStore) ensureDir() error {
+	return os.MkdirAll(s.cfg.DataDir, 0755)
 }
 
-func LoadTasks(d time.Time) []model.Task {
-	EnsureDir()
-	data, err := os.ReadFile(GetFilename(d))
+func (s *JSONStore) filename(d time.Time) string {
+	return filepath.Join(s.cfg.DataDir, fmt.Sprintf("tasks_%s.json", dayKey(d)))
+}
+
+func (s *JSONStore) scriptsFilename() string {
+	return filepath.Join(s.cfg.DataDir, "scripts.json")
+}
+
+func (s *JSONStore) recurrencesFilename() string {
+	return filepath.Join(s.cfg.DataDir, "recurrences.json")
+}
+
+func (s *JSONStore) filtersFilename() string {
+	return filepath.Join(s.cfg.DataDir, "filters.json")
+}
+
+func (s *JSONStore) trashFilename() string {
+	return filepath.Join(s.cfg.DataDir, "trash.json")
+}
+
+// LoadTasks returns the tasks stored for day d. A missing file is an empty
+// day; a file that cannot be parsed is quarantined and reported.
+func (s *JSONStore) LoadTasks(d time.Time) ([]model.Task, error) {
+	tasks, err := s.loadStored(d)
+	if err != nil {
+		return []model.Task{}, err
+	}
+	recs, err := s.LoadRecurrences()
 	if err != nil {
-		return []model.Task{}
+		return tasks, err
 	}
+	return materialize(d, tasks, recs), nil
+}
+
+// loadStored reads the day file without adding recurring instances.
+func (s *JSONStore) loadStored(d time.Time) ([]model.Task, error) {
 	var tasks []model.Task
-	_ = json.Unmarshal(data, &tasks)
-	return tasks
+	if err := s.readJSON(s.filename(d), &tasks); err != nil {
+		return nil, err
+	}
+	backfillIDs(d, tasks)
+	return tasks, nil
 }
 
-func SaveTasks(d time.Time, tasks []model.Task) {
-	EnsureDir()
-	data, _ := json.MarshalIndent(tasks, "", "  ")
-	_ = os.WriteFile(GetFilename(d), data, 0644)
+func (s *JSONStore) SaveTasks(d time.Time, tasks []model.Task) error {
+	return s.writeJSON(s.filename(d), tasks)
 }
 
-func LoadScripts() []model.Script {
-	EnsureDir()
-	data, err := os.ReadFile(filepath.Join(config.PersistenceDir, "scripts.json"))
-	if err != nil {
-		// Return default scripts if none exist
-		return []model.Script{
-			{Name: "hello-world", Command: "echo 'hello world'", Description: "prints hello world"},
+// SaveDays writes every day file before renaming any of them into place,
+// see writeFilesAtomic.
+func (s *JSONStore) SaveDays(days []DayTasks) error {
+	if err := s.ensureDir(); err != nil {
+		return err
+	}
+	files := make(map[string][]byte, len(days))
+	for _, day := range days {
+		data, err := json.MarshalIndent(day.Tasks, "", "  ")
+		if err != nil {
+			return err
 		}
+		files[s.filename(day.Date)] = data
+	}
+	return writeFilesAtomic(files, 0644, s.cfg.Backup)
+}
+
+func (s *JSONStore) LoadScripts() ([]model.Script, error) {
+	path := s.scriptsFilename()
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return DefaultScripts(), nil
 	}
 	var scripts []model.Script
-	_ = json.Unmarshal(data, &scripts)
-	return scripts
+	if err := s.readJSON(path, &scripts); err != nil {
+		return []model.Script{}, err
+	}
+	return scripts, nil
+}
+
+func (s *JSONStore) SaveScripts(scripts []model.Script) error {
+	return s.writeJSON(s.scriptsFilename(), scripts)
+}
+
+func (s *JSONStore) LoadRecurrences() ([]model.Recurrence, error) {
+	var recs []model.Recurrence
+	if err := s.readJSON(s.recurrencesFilename(), &recs); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+func (s *JSONStore) SaveRecurrences(recs []model.Recurrence) error {
+	return s.writeJSON(s.recurrencesFilename(), recs)
+}
+
+func (s *JSONStore) LoadFilters() ([]model.Filter, error) {
+	path := s.filtersFilename()
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return DefaultFilters(), nil
+	}
+	var filters []model.Filter
+	if err := s.readJSON(path, &filters); err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+func (s *JSONStore) SaveFilters(filters []model.Filter) error {
+	return s.writeJSON(s.filtersFilename(), filters)
+}
+
+func (s *JSONStore) LoadTrash() ([]model.TrashItem, error) {
+	var items []model.TrashItem
+	if err := s.readJSON(s.trashFilename(), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func SaveScripts(scripts []model.Script) {
-	EnsureDir()
-	data, _ := json.MarshalIndent(scripts, "", "  ")
-	_ = os.WriteFile(filepath.Join(config.PersistenceDir, "scripts.json"), data, 0644)
+func (s *JSONStore) SaveTrash(items []model.TrashItem) error {
+	return s.writeJSON(s.trashFilename(), items)
+}
+
+func (s *JSONStore) Dates() ([]time.Time, error) {
+	matches, err := filepath.Glob(filepath.Join(s.cfg.DataDir, "tasks_*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var dates []time.Time
+	for _, path := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "tasks_"), ".json")
+		d, err := parseDayKey(name)
+		if err != nil {
+			continue // not one of ours
+		}
+		dates = append(dates, d)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates, nil
+}
+
+// Query reads every day file in range. Unreadable days are skipped and
+// reported together once the scan is complete.
+func (s *JSONStore) Query(q Query) ([]DatedTask, error) {
+	dates, err := s.Dates()
+	if err != nil {
+		return nil, err
+	}
+	var out []DatedTask
+	var errs []error
+	for _, d := range dates {
+		if !q.includesDay(d) {
+			continue
+		}
+		tasks, err := s.loadStored(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, t := range tasks {
+			dt := DatedTask{Date: d, Task: t}
+			if q.matches(dt) {
+				out = append(out, dt)
+			}
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+func (s *JSONStore) writeJSON(path string, v any) error {
+	if err := s.ensureDir(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644, s.cfg.Backup)
+}
+
+// readJSON decodes path into v. A missing file leaves v untouched; a
+// corrupt one is quarantined unless the store is only inspecting.
+func (s *JSONStore) readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		if s.inspecting {
+			return fmt.Errorf("%s is corrupt: %w", filepath.Base(path), err)
+		}
+		dst, qerr := quarantine(path)
+		if qerr != nil {
+			return fmt.Errorf("%s is corrupt (%v) and could not be moved aside: %w", filepath.Base(path), err, qerr)
+		}
+		return fmt.Errorf("%s is corrupt, moved to %s: %w", filepath.Base(path), filepath.Base(dst), err)
+	}
+	return nil
+}
+
+// quarantine renames a corrupt file out of the way so the next save does not
+// overwrite it. Earlier quarantined copies are never replaced.
+func quarantine(path string) (string, error) {
+	dst := path + ".corrupt"
+	if _, err := os.Stat(dst); err == nil {
+		dst = fmt.Sprintf("%s.%s.corrupt", path, time.Now().Format("20060102T150405"))
+	}
+	return dst, os.Rename(path, dst)
 }
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"zenith/internal/config"
+	"zenith/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// Store persists tasks, grouped by day, and scripts.
+type Store interface {
+	LoadTasks(d time.Time) ([]model.Task, error)
+	SaveTasks(d time.Time, tasks []model.Task) error
+	// SaveDays writes several days at once: either every day is saved or,
+	// on error, none of them is changed.
+	SaveDays(days []DayTasks) error
+
+	LoadScripts() ([]model.Script, error)
+	SaveScripts(scripts []model.Script) error
+
+	// Recurring task definitions. LoadTasks adds their instances for the
+	// requested day; Dates and Query only see instances that were saved.
+	LoadRecurrences() ([]model.Recurrence, error)
+	SaveRecurrences(recs []model.Recurrence) error
+
+	// Saved queries, see package query.
+	LoadFilters() ([]model.Filter, error)
+	SaveFilters(filters []model.Filter) error
+
+	// Deleted tasks and scripts, see Discard and Restore.
+	LoadTrash() ([]model.TrashItem, error)
+	SaveTrash(items []model.TrashItem) error
+
+	// Dates lists the days that have stored tasks, oldest first.
+	Dates() ([]time.Time, error)
+	// Query returns the tasks matching q across all stored days, ordered by day.
+	Query(q Query) ([]DatedTask, error)
+
+	Close() error
+}
+
+// DayTasks is the task list of one day.
+type DayTasks struct {
+	Date  time.Time
+	Tasks []model.Task
+}
+
+// DatedTask is a task together with the day it is stored under.
+type DatedTask struct {
+	Date time.Time
+	Task model.Task
+}
+
+// Query selects tasks across days. Zero bounds are open-ended and a nil
+// Match accepts every task.
+type Query struct {
+	From, To time.Time // inclusive
+	Match    func(DatedTask) bool
+}
+
+func (q Query) includesDay(d time.Time) bool {
+	d = Day(d)
+	if !q.From.IsZero() && d.Before(Day(q.From)) {
+		return false
+	}
+	if !q.To.IsZero() && d.After(Day(q.To)) {
+		return false
+	}
+	return true
+}
+
+func (q Query) matches(dt DatedTask) bool {
+	return q.Match == nil || q.Match(dt)
+}
+
+// Open returns the store configured by cfg.
+func Open(cfg config.Config) (Store, error) {
+	switch cfg.Storage {
+	case "", config.StorageJSON:
+		return NewJSONStore(cfg), nil
+	case config.StorageSQLite:
+		return NewSQLiteStore(cfg.DatabasePath())
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage)
+	}
+}
+
+// OpenSpec opens a store described as "<backend>:<location>", for example
+// "json:/home/me/.zenith" or "sqlite:/home/me/zenith.db".
+func OpenSpec(spec string, backup bool) (Store, error) {
+	backend, location, ok := strings.Cut(spec, ":")
+	if !ok || location == "" {
+		return nil, fmt.Errorf("invalid store %q, expected <backend>:<location>", spec)
+	}
+	switch backend {
+	case config.StorageJSON:
+		return NewJSONStore(config.Config{DataDir: config.ExpandHome(location), Backup: backup}), nil
+	case config.StorageSQLite:
+		return NewSQLiteStore(config.ExpandHome(location))
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// Day truncates t to local midnight, the key tasks are grouped by.
+func Day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+const dayLayout = "2006-01-02"
+
+func dayKey(d time.Time) string {
+	return d.Format(dayLayout)
+}
+
+func parseDayKey(s string) (time.Time, error) {
+	return time.ParseInLocation(dayLayout, s, time.Local)
+}
+
+// DefaultScripts seeds the script list of a fresh store.
+func DefaultScripts() []model.Script {
+	return []model.Script{
+		{Name: "hello-world", Command: "echo 'hello world'", Description: "prints hello world"},
+	}
+}
+
+// DefaultFilters seeds the saved filters of a fresh store.
+func DefaultFilters() []model.Filter {
+	return []model.Filter{
+		{Name: "Overdue", Query: "is:overdue"},
+		{Name: "This week", Query: "date:this-week"},
+		{Name: "Work, high priority", Query: "tag:work priority:>=high status:open"},
+	}
+}
+
+// idNamespace seeds the IDs derived for tasks saved before IDs existed.
+var idNamespace = uuid.MustParse("6f1c1d7e-3b0a-4f5e-9a57-2d8c0b6e4a11")
+
+// backfillIDs gives tasks saved before IDs existed a stable ID derived from
+// where they are stored, so repeated loads agree without rewriting the file.
+// The derived ID is persisted the next time the day is saved.
+func backfillIDs(d time.Time, tasks []model.Task) {
+	for i := range tasks {
+		if tasks[i].ID == "" {
+			tasks[i].ID = legacyID(d, i, tasks[i])
+		}
+	}
+}
+
+func legacyID(d time.Time, position int, t model.Task) string {
+	name := fmt.Sprintf("%s/%d/%s/%s", dayKey(d), position, t.CreatedAt.Format(time.RFC3339Nano), t.Title)
+	return uuid.NewSHA1(idNamespace, []byte(name)).String()
+}
@@ -0,0 +1,559 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"zenith/internal/config"
+	"zenith/internal/model"
+)
+
+// stores opens a fresh store of every backend.
+func stores(t *testing.T) map[string]Store {
+	t.Helper()
+	db, err := NewSQLiteStore(filepath.Join(t.TempDir(), "zenith.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return map[string]Store{
+		"memory": NewMemoryStore(),
+		"json":   NewJSONStore(config.Config{DataDir: t.TempDir()}),
+		"sqlite": db,
+	}
+}
+
+// forEachStore runs the same test against every backend.
+func forEachStore(t *testing.T, test func(t *testing.T, s Store)) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) { test(t, s) })
+	}
+}
+
+// sameJSON fails the test unless got and want encode the same, which
+// compares times by instant rather than by location.
+func sameJSON(t *testing.T, what string, got, want any) {
+	t.Helper()
+	g, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(g) != string(w) {
+		t.Errorf("%s:\n got %s\nwant %s", what, g, w)
+	}
+}
+
+func mustSave(t *testing.T, s Store, day string, tasks ...model.Task) {
+	t.Helper()
+	if err := s.SaveTasks(date(day), tasks); err != nil {
+		t.Fatalf("SaveTasks(%s): %v", day, err)
+	}
+}
+
+// failing wraps a store so that writing the days in days, or the
+// recurrences when recs is set, fails.
+type failing struct {
+	Store
+	days map[string]bool
+	recs bool
+}
+
+var errWrite = errors.New("write failed")
+
+func (s failing) SaveTasks(d time.Time, tasks []model.Task) error {
+	if s.days[dayKey(d)] {
+		return errWrite
+	}
+	return s.Store.SaveTasks(d, tasks)
+}
+
+func (s failing) SaveDays(days []DayTasks) error {
+	for _, day := range days {
+		if s.days[dayKey(day.Date)] {
+			return errWrite
+		}
+	}
+	return s.Store.SaveDays(days)
+}
+
+func (s failing) SaveRecurrences(recs []model.Recurrence) error {
+	if s.recs {
+		return errWrite
+	}
+	return s.Store.SaveRecurrences(recs)
+}
+
+func titlesOf(tasks []DatedTask) []string {
+	out := make([]string, len(tasks))
+	for i, dt := range tasks {
+		out[i] = dayKey(dt.Date) + " " + dt.Task.Title
+	}
+	return out
+}
+
+func TestStoreFresh(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		tasks, err := s.LoadTasks(date("2026-03-04"))
+		if err != nil || len(tasks) != 0 {
+			t.Errorf("LoadTasks on a fresh store = %v, %v; want no tasks", tasks, err)
+		}
+		scripts, err := s.LoadScripts()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameJSON(t, "scripts", scripts, DefaultScripts())
+		filters, err := s.LoadFilters()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameJSON(t, "filters", filters, DefaultFilters())
+		if dates, err := s.Dates(); err != nil || len(dates) != 0 {
+			t.Errorf("Dates on a fresh store = %v, %v; want none", dates, err)
+		}
+	})
+}
+
+func TestStoreLoadSave(t *testing.T) {
+	created := time.Date(2026, time.March, 1, 8, 15, 30, 0, time.Local)
+	completed := created.Add(time.Hour)
+	tasks := []model.Task{
+		{
+			ID: "a", Title: "write report", CreatedAt: created, Priority: model.PriorityHigh,
+			Tags: []string{"work"}, Notes: "draft first\nthen review", Due: "11:00",
+			Subtasks: []model.Subtask{{Title: "outline"}},
+		},
+		{ID: "b", Title: "groceries", CreatedAt: created, Completed: true, CompletedAt: &completed},
+	}
+	scripts := []model.Script{{Name: "backup", Command: "rsync -a ~/notes /mnt", Description: "copies the notes"}}
+	recs := []model.Recurrence{{ID: "r", Rule: "FREQ=WEEKLY;BYDAY=MO", Start: "2026-03-02", Title: "plan week"}}
+	filters := []model.Filter{{Name: "Urgent", Query: "priority:urgent"}}
+	trash := []model.TrashItem{model.TrashTask(tasks[1], date("2026-02-27"), completed)}
+
+	forEachStore(t, func(t *testing.T, s Store) {
+		mustSave(t, s, "2026-03-04", tasks...)
+		got, err := s.LoadTasks(date("2026-03-04"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameJSON(t, "tasks", got, tasks)
+
+		// Saving again replaces the day, it does not append to it.
+		mustSave(t, s, "2026-03-04", tasks[1])
+		got, err = s.LoadTasks(date("2026-03-04"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameJSON(t, "tasks after saving one", got, tasks[1:])
+
+		if err := s.SaveScripts(scripts); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveRecurrences(recs); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveFilters(filters); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveTrash(trash); err != nil {
+			t.Fatal(err)
+		}
+		gotScripts, err := s.LoadScripts()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameJSON(t, "scripts", gotScripts, scripts)
+		gotRecs, err := s.LoadRecurrences()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameJSON(t, "recurrences", gotRecs, recs)
+		gotFilters, err := s.LoadFilters()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameJSON(t, "filters", gotFilters, filters)
+		gotTrash, err := s.LoadTrash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameJSON(t, "trash", gotTrash, trash)
+
+		// Deleting every saved filter is kept, not replaced by the defaults.
+		if err := s.SaveFilters(nil); err != nil {
+			t.Fatal(err)
+		}
+		if gotFilters, err = s.LoadFilters(); err != nil || len(gotFilters) != 0 {
+			t.Errorf("LoadFilters after saving none = %v, %v; want none", gotFilters, err)
+		}
+	})
+}
+
+func TestStoreSaveDays(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		mustSave(t, s, "2026-03-02", model.Task{ID: "old", Title: "old"})
+		mustSave(t, s, "2026-03-03", model.Task{ID: "kept", Title: "kept"})
+
+		err := s.SaveDays([]DayTasks{
+			{Date: date("2026-03-02"), Tasks: []model.Task{{ID: "new", Title: "replaced"}}},
+			{Date: date("2026-03-05"), Tasks: []model.Task{{ID: "x", Title: "added"}, {ID: "y", Title: "added too"}}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		all, err := s.Query(Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"2026-03-02 replaced", "2026-03-03 kept", "2026-03-05 added", "2026-03-05 added too"}
+		if got := titlesOf(all); !slices.Equal(got, want) {
+			t.Errorf("after SaveDays: %q, want %q", got, want)
+		}
+	})
+}
+
+func TestStoreDates(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		for _, day := range []string{"2026-03-10", "2025-12-31", "2026-03-02"} {
+			mustSave(t, s, day, model.Task{Title: "on " + day})
+		}
+		// Recurring instances that were never saved are not stored days.
+		if err := s.SaveRecurrences([]model.Recurrence{{ID: "r", Rule: "daily", Start: "2026-01-01", Title: "daily"}}); err != nil {
+			t.Fatal(err)
+		}
+
+		dates, err := s.Dates()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, len(dates))
+		for i, d := range dates {
+			got[i] = dayKey(d)
+			if !d.Equal(date(got[i])) {
+				t.Errorf("Dates returned %s, want local midnight", d)
+			}
+		}
+		if want := []string{"2025-12-31", "2026-03-02", "2026-03-10"}; !slices.Equal(got, want) {
+			t.Errorf("Dates = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestStoreQuery(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		mustSave(t, s, "2026-02-28", model.Task{Title: "a"})
+		mustSave(t, s, "2026-03-01", model.Task{Title: "b"}, model.Task{Title: "c", Completed: true})
+		mustSave(t, s, "2026-03-02", model.Task{Title: "d"})
+		mustSave(t, s, "2026-03-31", model.Task{Title: "e"})
+		if err := s.SaveRecurrences([]model.Recurrence{{ID: "r", Rule: "daily", Start: "2026-02-01", Title: "unsaved"}}); err != nil {
+			t.Fatal(err)
+		}
+
+		open := func(dt DatedTask) bool { return !dt.Task.Completed }
+		tests := []struct {
+			name string
+			q    Query
+			want []string
+		}{
+			{"all", Query{}, []string{"2026-02-28 a", "2026-03-01 b", "2026-03-01 c", "2026-03-02 d", "2026-03-31 e"}},
+			{"inclusive range", Query{From: date("2026-03-01"), To: date("2026-03-02")}, []string{"2026-03-01 b", "2026-03-01 c", "2026-03-02 d"}},
+			{"from only", Query{From: date("2026-03-02")}, []string{"2026-03-02 d", "2026-03-31 e"}},
+			{"to only", Query{To: date("2026-03-01")}, []string{"2026-02-28 a", "2026-03-01 b", "2026-03-01 c"}},
+			{"bounds within a day", Query{From: date("2026-03-01").Add(20 * time.Hour), To: date("2026-03-01").Add(time.Hour)}, []string{"2026-03-01 b", "2026-03-01 c"}},
+			{"empty range", Query{From: date("2026-03-03"), To: date("2026-03-30")}, nil},
+			{"match", Query{From: date("2026-03-01"), Match: open}, []string{"2026-03-01 b", "2026-03-02 d", "2026-03-31 e"}},
+		}
+		for _, tt := range tests {
+			got, err := s.Query(tt.q)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if titles := titlesOf(got); !slices.Equal(titles, tt.want) {
+				t.Errorf("%s: %q, want %q", tt.name, titles, tt.want)
+			}
+		}
+	})
+}
+
+func TestStoreLegacyIDs(t *testing.T) {
+	created := time.Date(2024, time.May, 6, 7, 8, 9, 0, time.Local)
+	legacy := []model.Task{
+		{Title: "saved before IDs", CreatedAt: created},
+		{ID: "has-one", Title: "already has an ID", CreatedAt: created},
+		{Title: "saved before IDs", CreatedAt: created}, // same fields, other position
+	}
+	day := date("2024-05-06")
+	want := []string{legacyID(day, 0, legacy[0]), "has-one", legacyID(day, 2, legacy[2])}
+
+	forEachStore(t, func(t *testing.T, s Store) {
+		mustSave(t, s, "2024-05-06", legacy...)
+
+		ids := func(tasks []model.Task) []string {
+			out := make([]string, len(tasks))
+			for i, task := range tasks {
+				out[i] = task.ID
+			}
+			return out
+		}
+		first, err := s.LoadTasks(day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(first); !slices.Equal(got, want) || got[0] == got[2] {
+			t.Fatalf("backfilled IDs = %q, want %q", got, want)
+		}
+		again, err := s.LoadTasks(day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(again); !slices.Equal(got, want) {
+			t.Errorf("IDs changed between loads: %q, then %q", want, got)
+		}
+
+		queried, err := s.Query(Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fromQuery []model.Task
+		for _, dt := range queried {
+			fromQuery = append(fromQuery, dt.Task)
+		}
+		if got := ids(fromQuery); !slices.Equal(got, want) {
+			t.Errorf("Query IDs = %q, want %q as from LoadTasks", got, want)
+		}
+
+		// Once saved, the backfilled IDs are stored and survive reordering.
+		mustSave(t, s, "2024-05-06", first[2], first[0], first[1])
+		saved, err := s.LoadTasks(day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := ids(saved), []string{want[2], want[0], want[1]}; !slices.Equal(got, want) {
+			t.Errorf("IDs after saving = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestMigrateRefusesOwnData(t *testing.T) {
+	src := NewMemoryStore()
+	mustSave(t, src, "2026-03-04", model.Task{ID: "a", Title: "from the source"})
+
+	for name, dst := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			// A fresh destination only holds defaults, which may be replaced.
+			report, err := Migrate(src, dst, MigrateOptions{DryRun: true})
+			if err != nil || len(report.DataConflicts) != 0 {
+				t.Fatalf("dry run into a fresh store: %v, conflicts %q", err, report.DataConflicts)
+			}
+
+			own := []model.Script{{Name: "mine", Command: "true"}}
+			ownFilters := []model.Filter{{Name: "Mine", Query: "tag:mine"}}
+			ownTrash := []model.TrashItem{model.TrashScript(model.Script{Name: "old"}, time.Now())}
+			if err := dst.SaveScripts(own); err != nil {
+				t.Fatal(err)
+			}
+			if err := dst.SaveFilters(ownFilters); err != nil {
+				t.Fatal(err)
+			}
+			if err := dst.SaveTrash(ownTrash); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err = Migrate(src, dst, MigrateOptions{})
+			if err == nil {
+				t.Fatal("Migrate over the destination's own data succeeded without Force")
+			}
+			if want := []string{"scripts", "filters", "trash"}; !slices.Equal(report.DataConflicts, want) {
+				t.Errorf("DataConflicts = %q, want %q", report.DataConflicts, want)
+			}
+			scripts, _ := dst.LoadScripts()
+			sameJSON(t, "scripts after the refused migration", scripts, own)
+			filters, _ := dst.LoadFilters()
+			sameJSON(t, "filters after the refused migration", filters, ownFilters)
+			trash, _ := dst.LoadTrash()
+			sameJSON(t, "trash after the refused migration", trash, ownTrash)
+			if dates, _ := dst.Dates(); len(dates) != 0 {
+				t.Errorf("the refused migration wrote days %v", dates)
+			}
+
+			if _, err := Migrate(src, dst, MigrateOptions{Force: true}); err != nil {
+				t.Fatalf("Migrate with Force: %v", err)
+			}
+			scripts, _ = dst.LoadScripts()
+			sameJSON(t, "scripts after Force", scripts, DefaultScripts())
+
+			// Once the destination holds the source's data, migrating again
+			// only conflicts on the days.
+			report, err = Migrate(src, dst, MigrateOptions{DryRun: true})
+			if err != nil || len(report.DataConflicts) != 0 || len(report.Conflicts) != 1 {
+				t.Errorf("dry run after Force: %v, day conflicts %v, data conflicts %q", err, report.Conflicts, report.DataConflicts)
+			}
+		})
+	}
+}
+
+func TestMigrateLeavesCorruptSource(t *testing.T) {
+	for _, file := range []string{"tasks_2026-03-04.json", "scripts.json"} {
+		t.Run(file, func(t *testing.T) {
+			dir := t.TempDir()
+			src := NewJSONStore(config.Config{DataDir: dir})
+			mustSave(t, src, "2026-03-05", model.Task{ID: "a", Title: "fine"})
+			path := filepath.Join(dir, file)
+			if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, opts := range []MigrateOptions{{DryRun: true}, {}} {
+				_, err := Migrate(src, NewMemoryStore(), opts)
+				if err == nil || !strings.Contains(err.Error(), file) {
+					t.Errorf("Migrate with %+v: err = %v, want one naming %s", opts, err, file)
+				}
+			}
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("the corrupt source file was moved: %v", err)
+			}
+			if moved, _ := filepath.Glob(filepath.Join(dir, "*.corrupt")); len(moved) > 0 {
+				t.Errorf("migrating quarantined %v", moved)
+			}
+		})
+	}
+}
+
+// fill gives s a bit of everything Migrate copies.
+func fill(t *testing.T, s Store) {
+	t.Helper()
+	created := time.Date(2026, time.March, 1, 8, 15, 30, 0, time.Local)
+	report := model.Task{ID: "a", Title: "write report", CreatedAt: created, Tags: []string{"work"}, Notes: "draft"}
+	mustSave(t, s, "2026-03-04", report, model.Task{ID: "b", Title: "groceries", CreatedAt: created})
+	mustSave(t, s, "2026-03-06", model.Task{ID: "c", Title: "call mum", Due: "18:00"})
+	if err := s.SaveScripts([]model.Script{{Name: "backup", Command: "rsync -a ~/notes /mnt"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveRecurrences([]model.Recurrence{{ID: "r", Rule: "weekly", Start: "2026-03-02", Title: "plan week"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveFilters([]model.Filter{{Name: "Work", Query: "tag:work"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveTrash([]model.TrashItem{model.TrashTask(report, date("2026-02-27"), created)}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	for _, pair := range [][2]string{{"json", "sqlite"}, {"sqlite", "json"}, {"memory", "json"}} {
+		t.Run(pair[0]+" to "+pair[1], func(t *testing.T) {
+			src, dst := stores(t)[pair[0]], stores(t)[pair[1]]
+			fill(t, src)
+			want, err := snapshot(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dry, err := Migrate(src, dst, MigrateOptions{DryRun: true})
+			if err != nil {
+				t.Fatalf("dry run: %v", err)
+			}
+			counts := [6]int{dry.Days, dry.Tasks, dry.Scripts, dry.Recurrences, dry.Filters, dry.Trash}
+			if counts != [6]int{2, 3, 1, 1, 1, 1} {
+				t.Errorf("dry run counts days, tasks, scripts, recurrences, filters, trash = %v", counts)
+			}
+			if dates, _ := dst.Dates(); len(dates) != 0 {
+				t.Errorf("the dry run wrote days %v", dates)
+			}
+			scripts, _ := dst.LoadScripts()
+			sameJSON(t, "scripts after the dry run", scripts, DefaultScripts())
+
+			report, err := Migrate(src, dst, MigrateOptions{})
+			if err != nil {
+				t.Fatalf("Migrate: %v", err)
+			}
+			if report.Checksum != dry.Checksum || report.Tasks != dry.Tasks {
+				t.Errorf("Migrate reported %+v, the dry run %+v", report, dry)
+			}
+			got, err := snapshot(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameJSON(t, "days", got.days, want.days)
+			sameJSON(t, "scripts", got.scripts, want.scripts)
+			sameJSON(t, "recurrences", got.recs, want.recs)
+			sameJSON(t, "filters", got.filters, want.filters)
+			sameJSON(t, "trash", got.trash, want.trash)
+
+			// Copying back into a fresh store of the source's kind ends
+			// where it started.
+			back := stores(t)[pair[0]]
+			again, err := Migrate(dst, back, MigrateOptions{})
+			if err != nil {
+				t.Fatalf("Migrate back: %v", err)
+			}
+			if again.Checksum != report.Checksum {
+				t.Errorf("checksum after the round trip = %s, want %s", again.Checksum, report.Checksum)
+			}
+		})
+	}
+}
+
+func TestMigrateDayConflicts(t *testing.T) {
+	src := NewMemoryStore()
+	fill(t, src)
+	forEachStore(t, func(t *testing.T, dst Store) {
+		mustSave(t, dst, "2026-03-06", model.Task{ID: "x", Title: "already here"})
+		mustSave(t, dst, "2026-03-07", model.Task{ID: "y", Title: "not migrated"})
+
+		report, err := Migrate(src, dst, MigrateOptions{})
+		if err == nil {
+			t.Fatal("Migrate over a day with tasks succeeded without Force")
+		}
+		if len(report.Conflicts) != 1 || dayKey(report.Conflicts[0]) != "2026-03-06" {
+			t.Errorf("Conflicts = %v, want 2026-03-06", report.Conflicts)
+		}
+		all, _ := dst.Query(Query{})
+		if got, want := titlesOf(all), []string{"2026-03-06 already here", "2026-03-07 not migrated"}; !slices.Equal(got, want) {
+			t.Errorf("after the refused migration the destination holds %q, want %q", got, want)
+		}
+
+		if _, err := Migrate(src, dst, MigrateOptions{Force: true}); err != nil {
+			t.Fatalf("Migrate with Force: %v", err)
+		}
+		all, _ = dst.Query(Query{})
+		want := []string{"2026-03-04 write report", "2026-03-04 groceries", "2026-03-06 call mum", "2026-03-07 not migrated"}
+		if got := titlesOf(all); !slices.Equal(got, want) {
+			t.Errorf("after Force the destination holds %q, want %q", got, want)
+		}
+	})
+}
+
+func TestSelect(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		mustSave(t, s, "2026-03-02", model.Task{ID: "a", Title: "write report", Tags: []string{"work"}})
+		mustSave(t, s, "2026-03-04", model.Task{ID: "b", Title: "groceries"}, model.Task{ID: "c", Title: "Report back"})
+		work := func(dt DatedTask) bool { return slices.Contains(dt.Task.Tags, "work") }
+
+		found, err := Search(s, "REPORT")
+		if got, want := titlesOf(found), []string{"2026-03-04 Report back", "2026-03-02 write report"}; err != nil || !slices.Equal(got, want) {
+			t.Errorf("Search = %q, %v; want %q", got, err, want)
+		}
+		found, err = Select(s, work)
+		if got, want := titlesOf(found), []string{"2026-03-02 write report"}; err != nil || !slices.Equal(got, want) {
+			t.Errorf("Select = %q, %v; want %q", got, err, want)
+		}
+
+		// Days saved after the first search are picked up.
+		mustSave(t, s, "2026-03-02", model.Task{ID: "a", Title: "write report"})
+		mustSave(t, s, "2026-03-05", model.Task{ID: "d", Title: "plan", Tags: []string{"work"}})
+		found, err = Select(s, work)
+		if got, want := titlesOf(found), []string{"2026-03-05 plan"}; err != nil || !slices.Equal(got, want) {
+			t.Errorf("Select after saving = %q, %v; want %q", got, err, want)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+	"zenith/internal/model"
+)
+
+// UpdateTask applies fn to the task with the given ID on day d and saves
+// the day, for callers that do not hold the day's task list.
+func UpdateTask(s Store, d time.Time, id string, fn func(*model.Task)) (model.Task, error) {
+	tasks, err := s.LoadTasks(d)
+	if err != nil {
+		return model.Task{}, err
+	}
+	idx := findTask(tasks, id)
+	if idx < 0 {
+		return model.Task{}, fmt.Errorf("no task %s on %s", id, dayKey(d))
+	}
+	fn(&tasks[idx])
+	return tasks[idx], s.SaveTasks(d, tasks)
+}
+
+// DeleteTask moves the task with the given ID from day d to the trash.
+// Deleting an instance of a recurring task records d as an exception of its
+// rule.
+func DeleteTask(s Store, d time.Time, id string) (model.Task, error) {
+	tasks, err := s.LoadTasks(d)
+	if err != nil {
+		return model.Task{}, err
+	}
+	idx := findTask(tasks, id)
+	if idx < 0 {
+		return model.Task{}, fmt.Errorf("no task %s on %s", id, dayKey(d))
+	}
+	t := tasks[idx]
+	// Trash it first: a failure after that leaves a copy, not nothing
+	if err := Discard(s, model.TrashTask(t, d, time.Now())); err != nil {
+		return t, err
+	}
+	if err := s.SaveTasks(d, append(tasks[:idx], tasks[idx+1:]...)); err != nil {
+		return t, err
+	}
+	return t, addException(s, t.RecurrenceID, d)
+}
+
+func findTask(tasks []model.Task, id string) int {
+	for i, t := range tasks {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// addException stops the recurrence with the given ID from materializing
+// on day d. An empty ID is not an error, so callers need not check first.
+func addException(s Store, recID string, d time.Time) error {
+	if recID == "" {
+		return nil
+	}
+	recs, err := s.LoadRecurrences()
+	if err != nil {
+		return err
+	}
+	for i := range recs {
+		if recs[i].ID == recID {
+			recs[i].Exceptions = append(recs[i].Exceptions, dayKey(d))
+			return s.SaveRecurrences(recs)
+		}
+	}
+	return nil
+}
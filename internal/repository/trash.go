@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+	"zenith/internal/model"
+)
+
+// Discard adds deleted items to the trash.
+func Discard(s Store, items ...model.TrashItem) error {
+	trash, err := s.LoadTrash()
+	if err != nil {
+		return err
+	}
+	return s.SaveTrash(append(trash, items...))
+}
+
+// Restore takes the item with the given ID out of the trash and puts it
+// back: a task on the day it was deleted from, a script at the end of the
+// script list.
+func Restore(s Store, id string) (model.TrashItem, error) {
+	trash, err := s.LoadTrash()
+	if err != nil {
+		return model.TrashItem{}, err
+	}
+	idx := trashIndex(trash, id)
+	if idx < 0 {
+		return model.TrashItem{}, fmt.Errorf("no item %s in the trash", id)
+	}
+	item := trash[idx]
+
+	switch {
+	case item.Task != nil:
+		day, err := parseDayKey(item.Date)
+		if err != nil {
+			return item, fmt.Errorf("trashed task %q: %w", item.Task.Title, err)
+		}
+		tasks, err := s.LoadTasks(day)
+		if err != nil {
+			return item, err
+		}
+		if findTask(tasks, item.Task.ID) >= 0 {
+			return item, fmt.Errorf("%q is already on %s", item.Task.Title, item.Date)
+		}
+		if err := s.SaveTasks(day, append(tasks, *item.Task)); err != nil {
+			return item, err
+		}
+	case item.Script != nil:
+		scripts, err := s.LoadScripts()
+		if err != nil {
+			return item, err
+		}
+		if err := s.SaveScripts(append(scripts, *item.Script)); err != nil {
+			return item, err
+		}
+	}
+	return item, s.SaveTrash(append(trash[:idx], trash[idx+1:]...))
+}
+
+// Purge deletes the items with the given IDs from the trash for good.
+func Purge(s Store, ids ...string) error {
+	_, err := purge(s, func(item model.TrashItem) bool {
+		for _, id := range ids {
+			if item.ID == id {
+				return true
+			}
+		}
+		return false
+	})
+	return err
+}
+
+// EmptyTrash purges every item and returns how many there were.
+func EmptyTrash(s Store) (int, error) {
+	return purge(s, func(model.TrashItem) bool { return true })
+}
+
+// PurgeExpired purges the items deleted more than retention before now and
+// returns how many there were. A retention of zero keeps everything.
+func PurgeExpired(s Store, retention time.Duration, now time.Time) (int, error) {
+	if retention <= 0 {
+		return 0, nil
+	}
+	cutoff := now.Add(-retention)
+	return purge(s, func(item model.TrashItem) bool { return item.DeletedAt.Before(cutoff) })
+}
+
+func purge(s Store, match func(model.TrashItem) bool) (int, error) {
+	trash, err := s.LoadTrash()
+	if err != nil {
+		return 0, err
+	}
+	var keep []model.TrashItem
+	for _, item := range trash {
+		if !match(item) {
+			keep = append(keep, item)
+		}
+	}
+	n := len(trash) - len(keep)
+	if n == 0 {
+		return 0, nil
+	}
+	return n, s.SaveTrash(keep)
+}
+
+func trashIndex(trash []model.TrashItem, id string) int {
+	for i, item := range trash {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
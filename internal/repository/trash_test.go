@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+	"time"
+	"zenith/internal/model"
+)
+
+func trashIDs(t *testing.T, s Store) []string {
+	t.Helper()
+	trash, err := s.LoadTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, item := range trash {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestRestore(t *testing.T) {
+	at := time.Date(2026, time.March, 4, 9, 0, 0, 0, time.Local)
+	forEachStore(t, func(t *testing.T, s Store) {
+		kept := model.Task{ID: "a", Title: "kept"}
+		gone := model.Task{ID: "b", Title: "gone"}
+		script := model.Script{Name: "deploy", Command: "make deploy"}
+		task, sc := model.TrashTask(gone, date("2026-03-02"), at), model.TrashScript(script, at)
+		mustSave(t, s, "2026-03-02", kept)
+		if err := s.SaveScripts(nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := Discard(s, task, sc); err != nil {
+			t.Fatal(err)
+		}
+
+		item, err := Restore(s, task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.ID != task.ID {
+			t.Errorf("Restore returned %s, want %s", item.ID, task.ID)
+		}
+		tasks, _ := s.LoadTasks(date("2026-03-02"))
+		sameJSON(t, "tasks after restoring", tasks, []model.Task{kept, gone})
+		if ids, want := trashIDs(t, s), []string{sc.ID}; !slices.Equal(ids, want) {
+			t.Errorf("trash after restoring the task = %q, want %q", ids, want)
+		}
+
+		if _, err := Restore(s, sc.ID); err != nil {
+			t.Fatal(err)
+		}
+		scripts, _ := s.LoadScripts()
+		sameJSON(t, "scripts after restoring", scripts, []model.Script{script})
+		if ids := trashIDs(t, s); len(ids) != 0 {
+			t.Errorf("trash after restoring everything = %q, want empty", ids)
+		}
+
+		if _, err := Restore(s, sc.ID); err == nil {
+			t.Error("restoring an item no longer in the trash succeeded")
+		}
+	})
+}
+
+func TestRestoreAlreadyOnDay(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		task := model.Task{ID: "a", Title: "twice"}
+		mustSave(t, s, "2026-03-02", task)
+		item := model.TrashTask(task, date("2026-03-02"), time.Now())
+		if err := Discard(s, item); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Restore(s, item.ID); err == nil {
+			t.Fatal("restoring a task that is still on its day succeeded")
+		}
+		tasks, _ := s.LoadTasks(date("2026-03-02"))
+		sameJSON(t, "tasks after the refused restore", tasks, []model.Task{task})
+		if ids, want := trashIDs(t, s), []string{item.ID}; !slices.Equal(ids, want) {
+			t.Errorf("trash after the refused restore = %q, want %q", ids, want)
+		}
+	})
+}
+
+func TestPurgeExpired(t *testing.T) {
+	now := time.Date(2026, time.March, 31, 12, 0, 0, 0, time.Local)
+	week := 7 * 24 * time.Hour
+	forEachStore(t, func(t *testing.T, s Store) {
+		old := model.TrashScript(model.Script{Name: "old"}, now.Add(-week-time.Minute))
+		edge := model.TrashScript(model.Script{Name: "edge"}, now.Add(-week))
+		recent := model.TrashScript(model.Script{Name: "recent"}, now.Add(-time.Hour))
+		if err := Discard(s, old, edge, recent); err != nil {
+			t.Fatal(err)
+		}
+
+		if n, err := PurgeExpired(s, 0, now); err != nil || n != 0 {
+			t.Errorf("PurgeExpired with no retention = %d, %v; want 0", n, err)
+		}
+		if ids := trashIDs(t, s); len(ids) != 3 {
+			t.Errorf("no retention purged items, %d left", len(ids))
+		}
+
+		n, err := PurgeExpired(s, week, now)
+		if err != nil || n != 1 {
+			t.Errorf("PurgeExpired = %d, %v; want 1", n, err)
+		}
+		if ids, want := trashIDs(t, s), []string{edge.ID, recent.ID}; !slices.Equal(ids, want) {
+			t.Errorf("trash after purging = %q, want %q", ids, want)
+		}
+	})
+}
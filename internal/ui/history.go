@@ -0,0 +1,339 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"zenith/internal/history"
+	"zenith/internal/model"
+	"zenith/internal/repository"
+)
+
+// historyLimit is how many changes a session can undo.
+const historyLimit = 100
+
+// daySnapshot is the stored state of some days and of the recurrence rules,
+// which deleting or moving an instance changes along with its day, and of
+// the trash deleted tasks go to.
+type daySnapshot struct {
+	days  []repository.DayTasks
+	recs  []model.Recurrence
+	trash []model.TrashItem
+}
+
+// check returns history.ErrConflict unless the store still holds the days
+// and recurrence rules of s, so that restoring a snapshot never overwrites
+// changes the edit did not make.
+func (s daySnapshot) check(store repository.Store) error {
+	for _, d := range s.days {
+		tasks, err := store.LoadTasks(d.Date)
+		if err != nil {
+			return err
+		}
+		if !sameJSON(tasks, d.Tasks) {
+			return fmt.Errorf("tasks of %s %w", d.Date.Format("Mon, Jan 2"), history.ErrConflict)
+		}
+	}
+	recs, err := store.LoadRecurrences()
+	if err != nil {
+		return err
+	}
+	if !sameJSON(recs, s.recs) {
+		return fmt.Errorf("repeat rules %w", history.ErrConflict)
+	}
+	return nil
+}
+
+func (s daySnapshot) restore(store repository.Store) error {
+	days := make([]repository.DayTasks, len(s.days))
+	for i, d := range s.days {
+		days[i] = repository.DayTasks{Date: d.Date, Tasks: cloneTasks(d.Tasks)}
+	}
+	if err := store.SaveDays(days); err != nil {
+		return err
+	}
+	return store.SaveRecurrences(slices.Clone(s.recs))
+}
+
+// dayEdit is an undoable change to tasks, kept as the state of the days it
+// touched before and after.
+type dayEdit struct {
+	store         repository.Store
+	desc          string
+	before, after daySnapshot
+}
+
+func (e dayEdit) Undo() error {
+	if err := e.after.check(e.store); err != nil {
+		return err
+	}
+	if err := e.before.restore(e.store); err != nil {
+		return err
+	}
+	return applyTrash(e.store, e.after.trash, e.before.trash)
+}
+
+func (e dayEdit) Redo() error {
+	if err := e.before.check(e.store); err != nil {
+		return err
+	}
+	if err := e.after.restore(e.store); err != nil {
+		return err
+	}
+	return applyTrash(e.store, e.before.trash, e.after.trash)
+}
+
+func (e dayEdit) String() string { return e.desc }
+
+// scriptSnapshot is the state of the scripts and the trash.
+type scriptSnapshot struct {
+	scripts []model.Script
+	trash   []model.TrashItem
+}
+
+// scriptEdit is an undoable change to the scripts.
+type scriptEdit struct {
+	store         repository.Store
+	desc          string
+	before, after scriptSnapshot
+}
+
+// check returns history.ErrConflict unless the store still holds the
+// scripts of s.
+func (s scriptSnapshot) check(store repository.Store) error {
+	scripts, err := store.LoadScripts()
+	if err != nil {
+		return err
+	}
+	if !sameJSON(scripts, s.scripts) {
+		return fmt.Errorf("scripts %w", history.ErrConflict)
+	}
+	return nil
+}
+
+func (e scriptEdit) Undo() error {
+	if err := e.after.check(e.store); err != nil {
+		return err
+	}
+	if err := e.store.SaveScripts(slices.Clone(e.before.scripts)); err != nil {
+		return err
+	}
+	return applyTrash(e.store, e.after.trash, e.before.trash)
+}
+
+func (e scriptEdit) Redo() error {
+	if err := e.before.check(e.store); err != nil {
+		return err
+	}
+	if err := e.store.SaveScripts(slices.Clone(e.after.scripts)); err != nil {
+		return err
+	}
+	return applyTrash(e.store, e.before.trash, e.after.trash)
+}
+
+func (e scriptEdit) String() string { return e.desc }
+
+// applyTrash changes the stored trash the way it changed from from to to.
+// Only the items that differ are touched, so undoing a delete does not
+// bring back items purged since.
+func applyTrash(store repository.Store, from, to []model.TrashItem) error {
+	has := func(items []model.TrashItem, id string) bool {
+		return slices.ContainsFunc(items, func(item model.TrashItem) bool { return item.ID == id })
+	}
+	var removed []string
+	for _, item := range from {
+		if !has(to, item.ID) {
+			removed = append(removed, item.ID)
+		}
+	}
+	var added []model.TrashItem
+	for _, item := range to {
+		if !has(from, item.ID) {
+			added = append(added, item)
+		}
+	}
+	if len(removed) == 0 && len(added) == 0 {
+		return nil
+	}
+
+	trash, err := store.LoadTrash()
+	if err != nil {
+		return err
+	}
+	trash = slices.DeleteFunc(trash, func(item model.TrashItem) bool { return slices.Contains(removed, item.ID) })
+	for _, item := range added {
+		if !has(trash, item.ID) {
+			trash = append(trash, item)
+		}
+	}
+	return store.SaveTrash(trash)
+}
+
+// Snapshot reads the stored state of the given days before a change, to be
+// passed to Record once the change is saved. It returns nil, and the change
+// is not recorded, if the store cannot be read.
+func (m *Model) Snapshot(days ...time.Time) *daySnapshot {
+	s, err := m.readSnapshot(days)
+	if err != nil {
+		m.Err = errors.Join(m.Err, err)
+		return nil
+	}
+	return s
+}
+
+func (m *Model) readSnapshot(days []time.Time) (*daySnapshot, error) {
+	s := &daySnapshot{}
+	for _, d := range days {
+		d = repository.Day(d)
+		if slices.ContainsFunc(s.days, func(dt repository.DayTasks) bool { return dt.Date.Equal(d) }) {
+			continue
+		}
+		tasks, err := m.Store.LoadTasks(d)
+		if err != nil {
+			return nil, err
+		}
+		s.days = append(s.days, repository.DayTasks{Date: d, Tasks: cloneTasks(tasks)})
+	}
+	var err error
+	if s.recs, err = m.Store.LoadRecurrences(); err != nil {
+		return nil, err
+	}
+	if s.trash, err = m.Store.LoadTrash(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Record adds the change made since before was taken to the undo history,
+// named by the format and args.
+func (m *Model) Record(before *daySnapshot, format string, args ...any) {
+	if before == nil {
+		return
+	}
+	dates := make([]time.Time, len(before.days))
+	for i, d := range before.days {
+		dates[i] = d.Date
+	}
+	after, err := m.readSnapshot(dates)
+	if err != nil {
+		m.Err = errors.Join(m.Err, err)
+		return
+	}
+	m.History.Push(dayEdit{store: m.Store, desc: fmt.Sprintf(format, args...), before: *before, after: *after})
+}
+
+// SnapshotScripts is Snapshot for a change to the scripts, to be passed to
+// RecordScripts.
+func (m *Model) SnapshotScripts() *scriptSnapshot {
+	trash, err := m.Store.LoadTrash()
+	if err != nil {
+		m.Err = errors.Join(m.Err, err)
+		return nil
+	}
+	return &scriptSnapshot{scripts: slices.Clone(m.Scripts), trash: trash}
+}
+
+// RecordScripts adds the change made to the scripts since before was taken
+// to the undo history.
+func (m *Model) RecordScripts(before *scriptSnapshot, format string, args ...any) {
+	if before == nil {
+		return
+	}
+	trash, err := m.Store.LoadTrash()
+	if err != nil {
+		m.Err = errors.Join(m.Err, err)
+		return
+	}
+	m.History.Push(scriptEdit{
+		store:  m.Store,
+		desc:   fmt.Sprintf(format, args...),
+		before: *before,
+		after:  scriptSnapshot{scripts: slices.Clone(m.Scripts), trash: trash},
+	})
+}
+
+// Undo reverts the last recorded change.
+func (m *Model) Undo() {
+	c, err := m.History.Undo()
+	switch {
+	case errors.Is(err, history.ErrConflict):
+		m.refreshAll()
+		m.Err = errors.Join(m.Err, fmt.Errorf("cannot undo %s, %w; the undo history was cleared", c, err))
+	case err != nil:
+		m.Err = fmt.Errorf("undo %s: %w", c, err)
+	case c == nil:
+		m.Status = "nothing to undo"
+	default:
+		m.refreshAll()
+		m.Status = "undid " + c.String()
+	}
+}
+
+// Redo applies the last undone change again.
+func (m *Model) Redo() {
+	c, err := m.History.Redo()
+	switch {
+	case errors.Is(err, history.ErrConflict):
+		m.refreshAll()
+		m.Err = errors.Join(m.Err, fmt.Errorf("cannot redo %s, %w; the undo history was cleared", c, err))
+	case err != nil:
+		m.Err = fmt.Errorf("redo %s: %w", c, err)
+	case c == nil:
+		m.Status = "nothing to redo"
+	default:
+		m.refreshAll()
+		m.Status = "redid " + c.String()
+	}
+}
+
+// refreshAll re-reads everything shown from the store.
+func (m *Model) refreshAll() {
+	scripts, err := m.Store.LoadScripts()
+	recs, recErr := m.Store.LoadRecurrences()
+	m.Scripts, m.Recurrences = scripts, recs
+	m.ClampScriptCursor()
+	m.Reload()
+	m.Err = errors.Join(m.Err, err, recErr)
+	switch m.ActiveTab {
+	case ListTab:
+		err, i := m.Err, m.ListTaskIndex
+		m.LoadList()
+		m.ListTaskIndex = i
+		m.ClampList()
+		m.Err = errors.Join(err, m.Err)
+	case CalendarTab:
+		m.LoadMonth()
+	case TrashTab:
+		m.LoadTrash()
+	}
+}
+
+// sameJSON reports whether a and b encode the same, treating nil and empty
+// as equal since backends differ in which they return for an empty day.
+func sameJSON[T any](a, b []T) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// cloneTasks copies tasks deeply enough that editing the copies in place
+// leaves the originals alone.
+func cloneTasks(tasks []model.Task) []model.Task {
+	out := make([]model.Task, len(tasks))
+	for i, t := range tasks {
+		t.Tags = slices.Clone(t.Tags)
+		t.Subtasks = slices.Clone(t.Subtasks)
+		if t.CompletedAt != nil {
+			at := *t.CompletedAt
+			t.CompletedAt = &at
+		}
+		out[i] = t
+	}
+	return out
+}
@@ -0,0 +1,286 @@
+package ui
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+	"zenith/internal/config"
+	"zenith/internal/history"
+	"zenith/internal/model"
+	"zenith/internal/repository"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// session drives a Model the way bubbletea would, one key at a time.
+type session struct {
+	t     *testing.T
+	model tea.Model
+	store repository.Store
+}
+
+func newSession(t *testing.T, store repository.Store) *session {
+	t.Helper()
+	cfg := config.Default()
+	cfg.DataDir = t.TempDir()
+	return &session{t: t, model: InitialModel(cfg, store), store: store}
+}
+
+// keys sends each argument as a key: named keys such as "enter", or runes.
+func (s *session) keys(keys ...string) {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "ctrl+r":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlR}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		s.model, _ = s.model.Update(msg)
+	}
+}
+
+func (s *session) m() Model { return s.model.(Model) }
+
+// titles returns the titles stored on d.
+func (s *session) titles(d time.Time) []string {
+	s.t.Helper()
+	tasks, err := s.store.LoadTasks(d)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	var out []string
+	for _, t := range tasks {
+		out = append(out, t.Title)
+	}
+	return out
+}
+
+func (s *session) expect(d time.Time, want ...string) {
+	s.t.Helper()
+	if got := s.titles(d); !slices.Equal(got, want) {
+		s.t.Errorf("%s holds %q, want %q", d.Format("2006-01-02"), got, want)
+	}
+}
+
+func TestUndoRollover(t *testing.T) {
+	today := repository.Day(time.Now())
+	yesterday := today.AddDate(0, 0, -1)
+	store := repository.NewMemoryStore()
+	if err := store.SaveTasks(yesterday, []model.Task{{ID: "r", Title: "unfinished report"}}); err != nil {
+		t.Fatal(err)
+	}
+	s := newSession(t, store)
+
+	s.keys("n", "x", "enter", "R")
+	s.expect(yesterday)
+	s.expect(today, "x", "unfinished report")
+
+	s.keys("u")
+	if got, want := s.m().Status, "undid carry over 1 unfinished task"; got != want {
+		t.Errorf("status after undo = %q, want %q", got, want)
+	}
+	s.expect(yesterday, "unfinished report")
+	s.expect(today, "x")
+
+	s.keys("u")
+	s.expect(yesterday, "unfinished report")
+	s.expect(today)
+
+	s.keys("ctrl+r", "ctrl+r")
+	s.expect(yesterday)
+	s.expect(today, "x", "unfinished report")
+}
+
+func TestUndoRefusesChangedDay(t *testing.T) {
+	today := repository.Day(time.Now())
+	store := repository.NewMemoryStore()
+	s := newSession(t, store)
+
+	s.keys("n", "x", "enter", "n", "y", "enter")
+	// A change the history did not see, e.g. by another process.
+	if err := store.SaveTasks(today, []model.Task{{ID: "z", Title: "from elsewhere"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.keys("u")
+	if err := s.m().Err; !errors.Is(err, history.ErrConflict) {
+		t.Fatalf("undo over a changed day: err = %v, want ErrConflict", err)
+	}
+	s.expect(today, "from elsewhere")
+
+	// The history was dropped, so older edits cannot be undone over it either.
+	s.keys("u")
+	if got := s.m().Status; got != "nothing to undo" {
+		t.Errorf("status after the conflict = %q, want nothing to undo", got)
+	}
+	s.expect(today, "from elsewhere")
+}
+
+func trashIDs(t *testing.T, store repository.Store) []string {
+	t.Helper()
+	items, err := store.LoadTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestApplyTrash(t *testing.T) {
+	at := time.Date(2026, time.March, 4, 9, 0, 0, 0, time.Local)
+	old := model.TrashTask(model.Task{Title: "old"}, at, at)
+	deleted := model.TrashTask(model.Task{Title: "deleted"}, at, at)
+	store := repository.NewMemoryStore()
+
+	// The old item was purged after deleted went to the trash. Undoing the
+	// delete takes deleted back out and must not restore old.
+	if err := store.SaveTrash([]model.TrashItem{deleted}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyTrash(store, []model.TrashItem{old, deleted}, []model.TrashItem{old}); err != nil {
+		t.Fatal(err)
+	}
+	if ids := trashIDs(t, store); len(ids) != 0 {
+		t.Errorf("trash after undoing the delete = %q, want empty", ids)
+	}
+
+	if err := applyTrash(store, []model.TrashItem{old}, []model.TrashItem{old, deleted}); err != nil {
+		t.Fatal(err)
+	}
+	if ids, want := trashIDs(t, store), []string{deleted.ID}; !slices.Equal(ids, want) {
+		t.Errorf("trash after redoing the delete = %q, want %q", ids, want)
+	}
+}
+
+func TestDayEdit(t *testing.T) {
+	d := repository.Day(time.Date(2026, time.March, 4, 0, 0, 0, 0, time.Local))
+	store := repository.NewMemoryStore()
+	kept := model.Task{ID: "a", Title: "kept"}
+	gone := model.Task{ID: "b", Title: "gone"}
+	item := model.TrashTask(gone, d, d)
+	if err := store.SaveTasks(d, []model.Task{kept, gone}); err != nil {
+		t.Fatal(err)
+	}
+	e := dayEdit{
+		store:  store,
+		desc:   `delete "gone"`,
+		before: daySnapshot{days: []repository.DayTasks{{Date: d, Tasks: []model.Task{kept, gone}}}},
+		after:  daySnapshot{days: []repository.DayTasks{{Date: d, Tasks: []model.Task{kept}}}, trash: []model.TrashItem{item}},
+	}
+	// Make the change the edit describes.
+	if err := store.SaveTasks(d, []model.Task{kept}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveTrash([]model.TrashItem{item}); err != nil {
+		t.Fatal(err)
+	}
+	s := &session{t: t, store: store}
+
+	if err := e.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	s.expect(d, "kept", "gone")
+	if ids := trashIDs(t, store); len(ids) != 0 {
+		t.Errorf("trash after undo = %q, want empty", ids)
+	}
+
+	if err := e.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	s.expect(d, "kept")
+	if ids, want := trashIDs(t, store), []string{item.ID}; !slices.Equal(ids, want) {
+		t.Errorf("trash after redo = %q, want %q", ids, want)
+	}
+
+	if err := store.SaveTasks(d, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Undo(); !errors.Is(err, history.ErrConflict) {
+		t.Errorf("Undo over a changed day = %v, want ErrConflict", err)
+	}
+	s.expect(d)
+}
+
+func TestScriptEdit(t *testing.T) {
+	store := repository.NewMemoryStore()
+	one := []model.Script{{Name: "one", Command: "true"}}
+	two := append(slices.Clone(one), model.Script{Name: "two", Command: "false"})
+	if err := store.SaveScripts(two); err != nil {
+		t.Fatal(err)
+	}
+	e := scriptEdit{store: store, desc: `add script "two"`, before: scriptSnapshot{scripts: one}, after: scriptSnapshot{scripts: two}}
+
+	names := func() []string {
+		scripts, err := store.LoadScripts()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, sc := range scripts {
+			out = append(out, sc.Name)
+		}
+		return out
+	}
+	if err := e.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(); !slices.Equal(got, []string{"one"}) {
+		t.Errorf("scripts after undo = %q, want [one]", got)
+	}
+	if err := e.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(); !slices.Equal(got, []string{"one", "two"}) {
+		t.Errorf("scripts after redo = %q, want [one two]", got)
+	}
+
+	if err := store.SaveScripts(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Undo(); !errors.Is(err, history.ErrConflict) {
+		t.Errorf("Undo over changed scripts = %v, want ErrConflict", err)
+	}
+	if got := names(); len(got) != 0 {
+		t.Errorf("the refused undo wrote scripts %q", got)
+	}
+}
+
+func TestDeleteRecurringInstance(t *testing.T) {
+	today := repository.Day(time.Now())
+	store := repository.NewMemoryStore()
+	rec := model.Recurrence{ID: "r", Rule: "daily", Start: today.AddDate(0, 0, -7).Format("2006-01-02"), Title: "water plants"}
+	if err := store.SaveRecurrences([]model.Recurrence{rec}); err != nil {
+		t.Fatal(err)
+	}
+	s := newSession(t, store)
+
+	s.keys("d")
+	s.expect(today)
+	if ids := trashIDs(t, store); len(ids) != 1 {
+		t.Errorf("trash after deleting holds %d items, want 1", len(ids))
+	}
+	recs, _ := store.LoadRecurrences()
+	if want := []string{today.Format("2006-01-02")}; !slices.Equal(recs[0].Exceptions, want) {
+		t.Errorf("exceptions after deleting = %q, want %q", recs[0].Exceptions, want)
+	}
+	if got := s.m().Recurrences; len(got) != 1 || len(got[0].Exceptions) != 1 {
+		t.Errorf("the model's rules were not reloaded: %+v", got)
+	}
+
+	s.keys("u")
+	s.expect(today, "water plants")
+	if ids := trashIDs(t, store); len(ids) != 0 {
+		t.Errorf("trash after undo = %q, want empty", ids)
+	}
+}
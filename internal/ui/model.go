@@ -1,11 +1,17 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
 	"sort"
 	"time"
+	"zenith/internal/config"
+	"zenith/internal/history"
 	"zenith/internal/model"
+	"zenith/internal/query"
 	"zenith/internal/repository"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -19,8 +25,20 @@ const (
 	SearchState
 	GotoDateState
 	HelpState
-	ScriptInputState // For adding/editing scripts
-	RunScriptState   // For answering placeholders
+	ScriptInputState  // For adding/editing scripts
+	RunScriptState    // For answering placeholders
+	TagState          // For setting the tags of a task
+	TagFilterState    // For choosing which tags to show
+	NotesState        // For editing the notes of a task
+	SubtaskState      // For adding a subtask
+	RolloverState     // For confirming the carry-over of unfinished tasks
+	RecurState        // For setting the recurrence rule of a task
+	DueState          // For setting the due time of a task
+	ScheduleState     // For choosing the day to move or copy a task to
+	GlobalSearchState // For searching the tasks of every day
+	ScriptSearchState // For filtering the scripts list
+	ListInputState    // For naming a saved list and writing its query
+	PurgeState        // For confirming a permanent delete from the trash
 )
 
 type Tab int
@@ -28,9 +46,21 @@ type Tab int
 const (
 	TaskTab Tab = iota
 	ScriptTab
+	CalendarTab
+	ListTab
+	TrashTab
+	tabCount
 )
 
+// DayCount summarizes one day in the calendar.
+type DayCount struct {
+	Done, Total int
+}
+
 type Model struct {
+	Config config.Config
+	Store  repository.Store
+
 	// Tabs
 	ActiveTab Tab
 
@@ -39,11 +69,47 @@ type Model struct {
 	Cursor       int
 	Page         int
 	SelectedDate time.Time
+	TagFilter    []string        // only tasks carrying all of these are listed
+	ShowDetail   bool            // show the detail pane next to the list
+	Expanded     map[string]bool // task IDs whose subtasks are shown
+	CopyTask     bool            // ScheduleState copies instead of moving
+
+	// Week view shows the seven days of SelectedDate's week, Monday first.
+	// The focused day is still held in Tasks, so every action works on it.
+	WeekView bool
+	Week     []repository.DayTasks
+
+	// Global search
+	SearchResults []repository.DatedTask
+	ResultCursor  int
+	QueryErr      error // why the search input is not a valid query
+
+	// Saved queries, shown in the Lists tab
+	Filters       []model.Filter
+	ListCursor    int                    // selected list
+	ListTasks     []repository.DatedTask // tasks matching it, newest day first
+	ListTaskIndex int                    // selected task in ListTasks
+	ListInputStep int                    // 0: Name, 1: Query
+	ActiveFilter  model.Filter           // list being created or edited
+	EditingList   bool
+
+	// Calendar
+	CalendarDate time.Time  // day under the calendar cursor
+	MonthCounts  []DayCount // one per day of CalendarDate's month
+
+	// Trash
+	TrashItems  []model.TrashItem // newest first
+	TrashCursor int
+	PurgeAll    bool // PurgeState empties the trash instead of purging one item
+
+	// Recurring task definitions
+	Recurrences []model.Recurrence
 
 	// Scripts
 	Scripts       []model.Script
 	ScriptCursor  int
 	ScriptPage    int
+	ScriptQuery   string        // fuzzy filter for the scripts list
 	PendingScript *model.Script // Script currently being run
 	ArgQueue      []string      // Placeholders waiting for input
 	ScriptArgs    map[string]string
@@ -57,13 +123,30 @@ type Model struct {
 	TextInput   textinput.Model
 	SearchInput textinput.Model
 	DateInput   textinput.Model
+	FilterInput textinput.Model
+	NotesInput  textarea.Model
 
 	State  sessionState
 	Width  int
 	Height int
+
+	// History holds the changes of this session for u and ctrl+r
+	History *history.History
+
+	// Err is the last storage error, shown in the footer until the next key
+	Err error
+	// Status is an informational message, shown like Err
+	Status string
+
+	PendingRollover int // unfinished tasks waiting for confirmation
+
+	// Now is the time of the last tick, used for due-time indicators
+	Now time.Time
+	// Reminder is the banner shown when a task's due time arrives
+	Reminder string
 }
 
-func InitialModel() Model {
+func InitialModel(cfg config.Config, store repository.Store) Model {
 	ti := textinput.New()
 	ti.Placeholder = " Description..."
 
@@ -71,32 +154,435 @@ func InitialModel() Model {
 	si.Placeholder = " Search..."
 
 	di := textinput.New()
-	di.Placeholder = " YYYY-MM-DD"
-	di.CharLimit = 10
+	di.Placeholder = " today, -1w, jan 5, YYYY-MM-DD..."
+	di.CharLimit = 20
 
-	now := time.Now()
+	fi := textinput.New()
+	fi.Placeholder = " work, home..."
+
+	ni := textarea.New()
+	ni.Placeholder = "Notes..."
+	ni.ShowLineNumbers = false
+	ni.CharLimit = 0
+
+	scripts, err := store.LoadScripts()
+	recs, recErr := store.LoadRecurrences()
+	filters, filterErr := store.LoadFilters()
 	m := Model{
-		ActiveTab:    TaskTab,
-		Tasks:        repository.LoadTasks(now),
-		Scripts:      repository.LoadScripts(),
-		SelectedDate: now,
-		TextInput:    ti,
-		SearchInput:  si,
-		DateInput:    di,
-		State:        ViewState,
-		ScriptArgs:   make(map[string]string),
+		Config:      cfg,
+		Store:       store,
+		ActiveTab:   TaskTab,
+		Scripts:     scripts,
+		Recurrences: recs,
+		Filters:     filters,
+		TextInput:   ti,
+		SearchInput: si,
+		DateInput:   di,
+		FilterInput: fi,
+		NotesInput:  ni,
+		State:       ViewState,
+		ScriptArgs:  make(map[string]string),
+		Expanded:    make(map[string]bool),
+		History:     history.New(historyLimit),
+		Now:         time.Now(),
+	}
+	m.LoadDay(time.Now())
+	m.Err = errors.Join(m.Err, err, recErr, filterErr)
+
+	if n, err := repository.PurgeExpired(store, cfg.TrashRetention(), time.Now()); err != nil {
+		m.Err = errors.Join(m.Err, err)
+	} else if n > 0 {
+		m.Status = fmt.Sprintf("purged %s older than %d days from the trash", plural(n, "item"), cfg.TrashRetentionDays)
+	}
+
+	switch cfg.Rollover {
+	case config.RolloverAuto:
+		m.Rollover()
+	case config.RolloverPrompt:
+		stale, err := repository.Unfinished(store, time.Now())
+		m.Err = errors.Join(m.Err, err)
+		if len(stale) > 0 {
+			m.PendingRollover = len(stale)
+			m.State = RolloverState
+		}
 	}
-	m.SortTasks()
 	return m
 }
 
+// Rollover carries unfinished tasks from past days over to today.
+func (m *Model) Rollover() {
+	now := time.Now()
+	days := []time.Time{now}
+	stale, _ := repository.Unfinished(m.Store, now) // Rollover reports the error
+	for _, dt := range stale {
+		days = append(days, dt.Date)
+	}
+	before := m.Snapshot(days...)
+	n, err := repository.Rollover(m.Store, now, m.Config.RolloverCopy)
+	if n > 0 {
+		m.Record(before, "carry over %s", plural(n, "unfinished task"))
+	}
+	if m.WeekView || repository.Day(m.SelectedDate).Equal(repository.Day(time.Now())) {
+		m.Reload()
+	}
+	m.Err = errors.Join(m.Err, err)
+	if n == 0 {
+		m.Status = "nothing to carry over"
+	} else {
+		m.Status = "carried " + plural(n, "unfinished task") + " over to today"
+	}
+}
+
+// LoadDay switches the task list to day d.
+func (m *Model) LoadDay(d time.Time) {
+	m.SelectedDate = d
+	m.Tasks, m.Err = m.Store.LoadTasks(d)
+	m.SortTasks()
+	m.Page, m.Cursor = 0, 0
+	m.syncWeek()
+}
+
+// syncWeek copies the focused day into the week view, loading the whole
+// week first when SelectedDate left the one on screen.
+func (m *Model) syncWeek() {
+	if !m.WeekView {
+		return
+	}
+	start := weekStart(m.SelectedDate)
+	if len(m.Week) != 7 || !m.Week[0].Date.Equal(start) {
+		m.Week = make([]repository.DayTasks, 7)
+		for i := range m.Week {
+			d := start.AddDate(0, 0, i)
+			tasks, err := m.Store.LoadTasks(d)
+			m.Err = errors.Join(m.Err, err)
+			sortTasks(tasks)
+			m.Week[i] = repository.DayTasks{Date: d, Tasks: tasks}
+		}
+	}
+	m.Week[m.WeekDay()].Tasks = m.Tasks
+}
+
+// WeekDay returns the position of SelectedDate in its week, Monday being 0.
+func (m Model) WeekDay() int {
+	return (int(m.SelectedDate.Weekday()) + 6) % 7
+}
+
+// weekStart returns local midnight of the Monday starting d's week.
+func weekStart(d time.Time) time.Time {
+	d = repository.Day(d)
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
+
+// Reload re-reads the selected day, and the rest of the week in week view,
+// after the store was changed by more than SaveTasks, keeping the cursor.
+func (m *Model) Reload() {
+	page, cursor := m.Page, m.Cursor
+	m.Week = nil
+	m.LoadDay(m.SelectedDate)
+	m.Page, m.Cursor = page, cursor
+	m.ClampCursor()
+}
+
+func (m *Model) SaveTasks() {
+	m.Err = m.Store.SaveTasks(m.SelectedDate, m.Tasks)
+	m.syncWeek()
+}
+
+// DeleteTask moves the selected task of the day to the trash.
+func (m *Model) DeleteTask() {
+	idx := m.RealIndex()
+	if idx < 0 {
+		return
+	}
+	before := m.Snapshot(m.SelectedDate)
+	t, err := repository.DeleteTask(m.Store, m.SelectedDate, m.Tasks[idx].ID)
+	if err != nil {
+		m.Err = err
+		return
+	}
+	m.Record(before, "delete %q", t.Title)
+	m.Reload()
+	if t.RecurrenceID != "" {
+		// The day was added to the rule's exceptions
+		var err error
+		m.Recurrences, err = m.Store.LoadRecurrences()
+		m.Err = errors.Join(m.Err, err)
+	}
+	m.Status = fmt.Sprintf("moved %q to the trash • u: undo", t.Title)
+}
+
+// MoveTask moves or copies the selected task to day d.
+func (m *Model) MoveTask(d time.Time) {
+	idx := m.RealIndex()
+	if idx < 0 {
+		return
+	}
+	before := m.Snapshot(m.SelectedDate, d)
+	t, err := repository.MoveTask(m.Store, m.SelectedDate, m.Tasks[idx].ID, d, m.CopyTask)
+	if err != nil {
+		m.Err = err
+		return
+	}
+	recurring := m.Tasks[idx].RecurrenceID != ""
+
+	m.Reload()
+	if recurring && !m.CopyTask {
+		// The old day was added to the rule's exceptions
+		var err error
+		m.Recurrences, err = m.Store.LoadRecurrences()
+		m.Err = errors.Join(m.Err, err)
+	}
+
+	verb, done := "move", "moved"
+	if m.CopyTask {
+		verb, done = "copy", "copied"
+	}
+	m.Record(before, "%s %q to %s", verb, t.Title, d.Format("Mon, Jan 2"))
+	m.Status = fmt.Sprintf("%s %q to %s", done, t.Title, d.Format("Mon, Jan 2"))
+}
+
+// OpenResult shows the day of a search result with the cursor on its task.
+func (m *Model) OpenResult(r repository.DatedTask) {
+	m.ActiveTab = TaskTab
+	if !hasAllTags(r.Task, m.TagFilter) {
+		m.TagFilter = nil
+	}
+	m.LoadDay(r.Date)
+	m.FocusTask(r.Task.ID)
+}
+
+// LoadMonth counts the tasks of every day in CalendarDate's month.
+func (m *Model) LoadMonth() {
+	y, mon, _ := m.CalendarDate.Date()
+	days := time.Date(y, mon+1, 0, 0, 0, 0, 0, time.Local).Day()
+	m.MonthCounts = make([]DayCount, days)
+	for i := range m.MonthCounts {
+		tasks, err := m.Store.LoadTasks(time.Date(y, mon, i+1, 0, 0, 0, 0, time.Local))
+		if err != nil {
+			m.Err = errors.Join(m.Err, err)
+			continue
+		}
+		for _, t := range tasks {
+			m.MonthCounts[i].Total++
+			if t.Completed {
+				m.MonthCounts[i].Done++
+			}
+		}
+	}
+}
+
+// MoveCalendar moves the calendar cursor to d, reloading the counts when
+// it leaves the month on screen.
+func (m *Model) MoveCalendar(d time.Time) {
+	d = repository.Day(d)
+	sameMonth := d.Year() == m.CalendarDate.Year() && d.Month() == m.CalendarDate.Month()
+	m.CalendarDate = d
+	if !sameMonth || m.MonthCounts == nil {
+		m.LoadMonth()
+	}
+}
+
+// LoadList runs the selected saved query over every day.
+func (m *Model) LoadList() {
+	m.ListTasks = nil
+	m.ClampList()
+	if len(m.Filters) == 0 {
+		return
+	}
+	expr, err := query.Parse(m.Filters[m.ListCursor].Query, time.Now())
+	if err != nil {
+		m.Err = err
+		return
+	}
+	m.ListTasks, m.Err = repository.Select(m.Store, func(dt repository.DatedTask) bool {
+		return expr.Match(dt.Task, dt.Date)
+	})
+	m.ClampList()
+}
+
+// ClampList keeps the list and task cursors of the Lists tab in range.
+func (m *Model) ClampList() {
+	m.ListCursor = max(0, min(m.ListCursor, len(m.Filters)-1))
+	m.ListTaskIndex = max(0, min(m.ListTaskIndex, len(m.ListTasks)-1))
+}
+
+// SelectedListTask returns the task under the cursor in the Lists tab.
+func (m Model) SelectedListTask() (repository.DatedTask, bool) {
+	if m.ListTaskIndex >= len(m.ListTasks) {
+		return repository.DatedTask{}, false
+	}
+	return m.ListTasks[m.ListTaskIndex], true
+}
+
+// UpdateListTask applies fn to the selected task of the Lists tab, on
+// whichever day it is stored, and refreshes the list and the day on screen.
+// The change is recorded for undo as verb and the task's title.
+func (m *Model) UpdateListTask(verb string, fn func(*model.Task)) {
+	dt, ok := m.SelectedListTask()
+	if !ok {
+		return
+	}
+	before := m.Snapshot(dt.Date)
+	if _, err := repository.UpdateTask(m.Store, dt.Date, dt.Task.ID, fn); err != nil {
+		m.Err = err
+		return
+	}
+	m.Record(before, "%s %q", verb, dt.Task.Title)
+	m.refreshList()
+}
+
+// DeleteListTask deletes the selected task of the Lists tab.
+func (m *Model) DeleteListTask() {
+	dt, ok := m.SelectedListTask()
+	if !ok {
+		return
+	}
+	before := m.Snapshot(dt.Date)
+	t, err := repository.DeleteTask(m.Store, dt.Date, dt.Task.ID)
+	if err != nil {
+		m.Err = err
+		return
+	}
+	m.Record(before, "delete %q", t.Title)
+	if t.RecurrenceID != "" {
+		m.Recurrences, err = m.Store.LoadRecurrences()
+	}
+	m.refreshList()
+	m.Err = errors.Join(m.Err, err)
+	m.Status = fmt.Sprintf("moved %q from %s to the trash • u: undo", t.Title, dt.Date.Format("Mon, Jan 2"))
+}
+
+func (m *Model) refreshList() {
+	i := m.ListTaskIndex
+	m.Reload()
+	err := m.Err
+	m.LoadList()
+	m.ListTaskIndex = i
+	m.ClampList()
+	m.Err = errors.Join(err, m.Err)
+}
+
+// SaveFilters stores the saved lists and reruns the selected one.
+func (m *Model) SaveFilters() {
+	err := m.Store.SaveFilters(m.Filters)
+	m.LoadList()
+	m.Err = errors.Join(err, m.Err)
+}
+
+// LoadTrash reads the trash, newest deletion first.
+func (m *Model) LoadTrash() {
+	m.TrashItems, m.Err = m.Store.LoadTrash()
+	sort.SliceStable(m.TrashItems, func(i, j int) bool {
+		return m.TrashItems[i].DeletedAt.After(m.TrashItems[j].DeletedAt)
+	})
+	m.TrashCursor = max(0, min(m.TrashCursor, len(m.TrashItems)-1))
+}
+
+// RestoreTrash puts the selected trash item back where it was deleted from.
+func (m *Model) RestoreTrash() {
+	if m.TrashCursor >= len(m.TrashItems) {
+		return
+	}
+	item := m.TrashItems[m.TrashCursor]
+	if item.Task != nil {
+		day, err := time.ParseInLocation("2006-01-02", item.Date, time.Local)
+		if err != nil {
+			m.Err = err
+			return
+		}
+		before := m.Snapshot(day)
+		if _, err := repository.Restore(m.Store, item.ID); err != nil {
+			m.Err = err
+			return
+		}
+		m.Record(before, "restore %q", item.Title())
+		m.Reload()
+		m.Status = fmt.Sprintf("restored %q to %s", item.Title(), day.Format("Mon, Jan 2"))
+	} else {
+		before := m.SnapshotScripts()
+		if _, err := repository.Restore(m.Store, item.ID); err != nil {
+			m.Err = err
+			return
+		}
+		var err error
+		m.Scripts, err = m.Store.LoadScripts()
+		m.Err = errors.Join(m.Err, err)
+		m.RecordScripts(before, "restore script %q", item.Title())
+		m.Status = fmt.Sprintf("restored script %q", item.Title())
+	}
+	err := m.Err
+	m.LoadTrash()
+	m.Err = errors.Join(err, m.Err)
+}
+
+// PurgeTrash deletes the selected trash item, or with PurgeAll every item,
+// for good. Purging cannot be undone.
+func (m *Model) PurgeTrash() {
+	if len(m.TrashItems) == 0 {
+		return
+	}
+	if m.PurgeAll {
+		n, err := repository.EmptyTrash(m.Store)
+		m.Err = err
+		m.Status = fmt.Sprintf("purged %s", plural(n, "item"))
+	} else {
+		item := m.TrashItems[m.TrashCursor]
+		m.Err = repository.Purge(m.Store, item.ID)
+		m.Status = fmt.Sprintf("purged %q", item.Title())
+	}
+	err := m.Err
+	m.LoadTrash()
+	m.Err = errors.Join(err, m.Err)
+}
+
+func (m *Model) SaveRecurrences() {
+	m.Err = m.Store.SaveRecurrences(m.Recurrences)
+}
+
+// RecurrenceIndex returns the position of the recurrence with the given ID
+// in m.Recurrences.
+func (m Model) RecurrenceIndex(id string) int {
+	for i, r := range m.Recurrences {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Model) SaveScripts() {
+	m.Err = m.Store.SaveScripts(m.Scripts)
+}
+
 func (m *Model) SortTasks() {
-	sort.SliceStable(m.Tasks, func(i, j int) bool {
-		if m.Tasks[i].Completed != m.Tasks[j].Completed {
-			return !m.Tasks[i].Completed
+	sortTasks(m.Tasks)
+}
+
+func sortTasks(tasks []model.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Completed != tasks[j].Completed {
+			return !tasks[i].Completed
+		}
+		if !tasks[i].Completed && tasks[i].Due != tasks[j].Due {
+			// Timed tasks first, in the order they are due
+			if tasks[i].Due == "" || tasks[j].Due == "" {
+				return tasks[i].Due != ""
+			}
+			return tasks[i].Due < tasks[j].Due
+		}
+		if !tasks[i].Completed && tasks[i].Priority != tasks[j].Priority {
+			return tasks[i].Priority > tasks[j].Priority
 		}
-		return m.Tasks[i].CreatedAt.Before(m.Tasks[j].CreatedAt)
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
 	})
 }
 
-func (m Model) Init() tea.Cmd { return nil }
+func (m Model) Init() tea.Cmd { return tick() }
+
+// tickMsg refreshes due-time indicators and fires reminders.
+type tickMsg time.Time
+
+// tick fires on every wall-clock minute, when due times can pass.
+func tick() tea.Cmd {
+	return tea.Every(time.Minute, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
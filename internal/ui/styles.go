@@ -1,12 +1,16 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"zenith/internal/model"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 var (
 	AccentColor = lipgloss.Color("#94B4C1")
 	GrayColor   = lipgloss.Color("#E4E4E4")
 
-	RedColor    = lipgloss.Color("#F39EB6")
+	RedColor = lipgloss.Color("#F39EB6")
 
 	HeaderStyle = lipgloss.NewStyle().
 			Background(AccentColor).
@@ -18,11 +22,47 @@ var (
 			Foreground(AccentColor).
 			Bold(true)
 
-	CursorCol = lipgloss.NewStyle().Width(3)
-	CheckCol  = lipgloss.NewStyle().Width(3)
+	CursorCol   = lipgloss.NewStyle().Width(3)
+	CheckCol    = lipgloss.NewStyle().Width(3)
+	PriorityCol = lipgloss.NewStyle().Width(4)
 
-	HelpKeyStyle   = lipgloss.NewStyle().Foreground(AccentColor).Bold(true)
-	HelpValueStyle = lipgloss.NewStyle().Foreground(GrayColor)
-	GrayTextStyle  = lipgloss.NewStyle().Foreground(GrayColor)
+	HelpKeyStyle    = lipgloss.NewStyle().Foreground(AccentColor).Bold(true)
+	HelpValueStyle  = lipgloss.NewStyle().Foreground(GrayColor)
+	GrayTextStyle   = lipgloss.NewStyle().Foreground(GrayColor)
 	FooterTextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#EAE0CF"))
+	ErrorStyle      = lipgloss.NewStyle().Foreground(RedColor).Bold(true)
+	StatusStyle     = lipgloss.NewStyle().Foreground(AccentColor)
+	UpcomingStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#F2D388")).Bold(true)
+	ReminderStyle   = lipgloss.NewStyle().Background(RedColor).Foreground(lipgloss.Color("#213448")).Bold(true)
+	MatchStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#F2D388")).Bold(true).Underline(true)
+	TagStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("#213448")).Background(lipgloss.Color("#ECEFCA"))
+	DetailStyle     = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder(), false, false, false, true).
+			BorderForeground(AccentColor).
+			PaddingLeft(1)
 )
+
+// HeatStyles color calendar days by the share of their tasks that are done,
+// from none to all.
+var HeatStyles = []lipgloss.Style{
+	lipgloss.NewStyle().Foreground(lipgloss.Color("#213448")).Background(RedColor),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("#213448")).Background(lipgloss.Color("#F4A261")),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("#213448")).Background(lipgloss.Color("#F2D388")),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("#213448")).Background(lipgloss.Color("#8FB996")),
+}
+
+// PriorityStyles colors the priority marker shown before a task.
+var PriorityStyles = map[model.Priority]lipgloss.Style{
+	model.PriorityLow:    lipgloss.NewStyle().Foreground(lipgloss.Color("#8FB996")),
+	model.PriorityMedium: lipgloss.NewStyle().Foreground(lipgloss.Color("#F2D388")).Bold(true),
+	model.PriorityHigh:   lipgloss.NewStyle().Foreground(lipgloss.Color("#F4A261")).Bold(true),
+	model.PriorityUrgent: lipgloss.NewStyle().Foreground(RedColor).Bold(true),
+}
+
+// PriorityMarkers are rendered in PriorityCol, one per level.
+var PriorityMarkers = map[model.Priority]string{
+	model.PriorityLow:    "·",
+	model.PriorityMedium: "!",
+	model.PriorityHigh:   "!!",
+	model.PriorityUrgent: "!!!",
+}
@@ -1,12 +1,19 @@
 package ui
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"time"
+	"zenith/internal/dateparse"
+	"zenith/internal/fuzzy"
 	"zenith/internal/model"
+	"zenith/internal/query"
+	"zenith/internal/recur"
 	"zenith/internal/repository"
 	"zenith/internal/script"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -14,18 +21,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case tickMsg:
+		m.CheckReminders(time.Time(msg))
+		return m, tick()
+
 	case tea.WindowSizeMsg:
 		m.Width, m.Height = msg.Width, msg.Height
 		m.ClampCursor()
+		m.NotesInput.SetWidth(m.DetailWidth() - 2)
+		m.NotesInput.SetHeight(m.PageSize() - 2)
 
 	case tea.KeyMsg:
+		m.Err = nil
+		m.Status = ""
+		m.Reminder = ""
+
 		// --- GLOBAL KEYS ---
 		switch msg.String() {
 		case "tab":
-			if m.ActiveTab == TaskTab {
-				m.ActiveTab = ScriptTab
-			} else {
-				m.ActiveTab = TaskTab
+			m.LeaveState()
+			m.ActiveTab = (m.ActiveTab + 1) % tabCount
+			if m.ActiveTab == CalendarTab {
+				// Counts may have changed since the calendar was last shown
+				m.MonthCounts = nil
+				m.MoveCalendar(m.SelectedDate)
+			}
+			if m.ActiveTab == ListTab {
+				m.LoadList()
+			}
+			if m.ActiveTab == TrashTab {
+				m.LoadTrash()
 			}
 			return m, nil
 		case "ctrl+c":
@@ -38,6 +63,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		
+		// --- ROLLOVER PROMPT ---
+		if m.State == RolloverState {
+			switch msg.String() {
+			case "y", "enter":
+				m.State = ViewState
+				m.Rollover()
+			case "n", "esc":
+				m.State = ViewState
+			}
+			m.PendingRollover = 0
+			return m, nil
+		}
+
+		// --- PURGE PROMPT ---
+		if m.State == PurgeState {
+			if msg.String() == "y" && m.ActiveTab == TrashTab {
+				m.PurgeTrash()
+			}
+			m.State = ViewState
+			m.PurgeAll = false
+			return m, nil
+		}
+
 		// --- RUN SCRIPT MODE (Arg Collection) ---
 		if m.State == RunScriptState {
 			switch msg.String() {
@@ -53,7 +101,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if len(m.ArgQueue) == 0 {
 					// All args collected, run the script
 					finalCmd := script.ReplacePlaceholders(m.PendingScript.Command, m.ScriptArgs)
-					_ = script.Run(finalCmd) // TODO: Handle error or output
+					m.Err = script.Run(finalCmd)
 					m.State = ViewState
 					m.PendingScript = nil
 				}
@@ -72,16 +120,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.State == GotoDateState {
 			switch msg.String() {
 			case "enter":
-				if d, err := time.Parse("2006-01-02", m.DateInput.Value()); err == nil {
-					m.SelectedDate = d
-					m.Tasks = repository.LoadTasks(d)
-					m.SortTasks()
-					m.Page, m.Cursor = 0, 0
+				if d, err := dateparse.Parse(m.DateInput.Value(), time.Now()); err != nil {
+					m.Err = err
+				} else {
+					m.LoadDay(d)
+				}
+				m.DateInput.SetValue("")
+				m.State = ViewState
+			case "esc":
+				m.DateInput.SetValue("")
+				m.State = ViewState
+			default:
+				m.DateInput, cmd = m.DateInput.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
+		// --- SCHEDULE MODE ---
+		if m.State == ScheduleState {
+			switch msg.String() {
+			case "enter":
+				if d, err := dateparse.Parse(m.DateInput.Value(), time.Now()); err != nil {
+					m.Err = err
+				} else {
+					m.MoveTask(d)
 				}
 				m.DateInput.SetValue("")
+				m.DateInput.Placeholder = " today, -1w, jan 5, YYYY-MM-DD..."
 				m.State = ViewState
 			case "esc":
 				m.DateInput.SetValue("")
+				m.DateInput.Placeholder = " today, -1w, jan 5, YYYY-MM-DD..."
 				m.State = ViewState
 			default:
 				m.DateInput, cmd = m.DateInput.Update(msg)
@@ -90,6 +160,53 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// --- GLOBAL SEARCH MODE ---
+		if m.State == GlobalSearchState {
+			switch msg.String() {
+			case "enter":
+				if m.ResultCursor < len(m.SearchResults) {
+					m.OpenResult(m.SearchResults[m.ResultCursor])
+				}
+				fallthrough
+			case "esc":
+				m.SearchInput.SetValue("")
+				m.SearchInput.Placeholder = " Search..."
+				m.SearchResults, m.QueryErr = nil, nil
+				m.State = ViewState
+			case "up", "ctrl+p":
+				if m.ResultCursor > 0 {
+					m.ResultCursor--
+				}
+			case "down", "ctrl+n":
+				if m.ResultCursor < len(m.SearchResults)-1 {
+					m.ResultCursor++
+				}
+			default:
+				m.SearchInput, cmd = m.SearchInput.Update(msg)
+				m.SearchAll(m.SearchInput.Value())
+				return m, cmd
+			}
+			return m, nil
+		}
+
+		// --- SCRIPT SEARCH MODE ---
+		if m.State == ScriptSearchState {
+			switch msg.String() {
+			case "esc":
+				m.SearchInput.SetValue("")
+				m.ScriptQuery = ""
+				m.State = ViewState
+			case "enter":
+				m.State = ViewState // keep the filter until esc
+			default:
+				m.SearchInput, cmd = m.SearchInput.Update(msg)
+				m.ScriptQuery = m.SearchInput.Value()
+				m.ScriptPage, m.ScriptCursor = 0, 0
+				return m, cmd
+			}
+			return m, nil
+		}
+
 		// --- SEARCH MODE ---
 		if m.State == SearchState {
 			switch msg.String() {
@@ -107,6 +224,160 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// --- NOTES MODE ---
+		if m.State == NotesState {
+			switch msg.String() {
+			case "ctrl+s":
+				idx := m.RealIndex()
+				if idx >= 0 {
+					before := m.Snapshot(m.SelectedDate)
+					m.Tasks[idx].Notes = strings.TrimRight(m.NotesInput.Value(), "\n ")
+					m.SaveTasks()
+					m.Record(before, "edit notes of %q", m.Tasks[idx].Title)
+				}
+				m.NotesInput.Blur()
+				m.State = ViewState
+			case "esc":
+				m.NotesInput.Blur()
+				m.State = ViewState
+			default:
+				m.NotesInput, cmd = m.NotesInput.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
+		// --- SUBTASK MODE ---
+		if m.State == SubtaskState {
+			switch msg.String() {
+			case "enter":
+				title := strings.TrimSpace(m.TextInput.Value())
+				idx := m.RealIndex()
+				if title != "" && idx >= 0 {
+					before := m.Snapshot(m.SelectedDate)
+					id := m.Tasks[idx].ID
+					m.Tasks[idx].Subtasks = append(m.Tasks[idx].Subtasks, model.Subtask{
+						ID:    model.NewID(),
+						Title: title,
+					})
+					m.Expanded[id] = true
+					m.SaveTasks()
+					m.Record(before, "add subtask %q", title)
+					m.FocusTask(id)
+				}
+				m.TextInput.SetValue("")
+				m.State = ViewState
+			case "esc":
+				m.TextInput.SetValue("")
+				m.State = ViewState
+			default:
+				m.TextInput, cmd = m.TextInput.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
+		// --- DUE TIME MODE ---
+		if m.State == DueState {
+			switch msg.String() {
+			case "enter":
+				idx := m.RealIndex()
+				var due string
+				var err error
+				if v := strings.TrimSpace(m.TextInput.Value()); v != "" {
+					due, err = model.ParseClock(v)
+				}
+				if err != nil {
+					m.Err = err
+				} else if idx >= 0 {
+					before := m.Snapshot(m.SelectedDate)
+					t := m.Tasks[idx]
+					m.Tasks[idx].Due = due
+					m.SortTasks()
+					m.SaveTasks()
+					m.Record(before, "set due time of %q", t.Title)
+					m.FocusTask(t.ID)
+				}
+				m.TextInput.SetValue("")
+				m.TextInput.Placeholder = " Description..."
+				m.State = ViewState
+			case "esc":
+				m.TextInput.SetValue("")
+				m.TextInput.Placeholder = " Description..."
+				m.State = ViewState
+			default:
+				m.TextInput, cmd = m.TextInput.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
+		// --- RECURRENCE MODE ---
+		if m.State == RecurState {
+			switch msg.String() {
+			case "enter":
+				before := m.Snapshot(m.SelectedDate)
+				if row, ok := m.SelectedRow(); ok {
+					if m.SetRecurrence(strings.TrimSpace(m.TextInput.Value())) {
+						m.Record(before, "set repeat rule of %q", row.Task.Title)
+					}
+				}
+				m.TextInput.SetValue("")
+				m.TextInput.Placeholder = " Description..."
+				m.State = ViewState
+			case "esc":
+				m.TextInput.SetValue("")
+				m.TextInput.Placeholder = " Description..."
+				m.State = ViewState
+			default:
+				m.TextInput, cmd = m.TextInput.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
+		// --- TAG MODE ---
+		if m.State == TagState {
+			switch msg.String() {
+			case "enter":
+				idx := m.RealIndex()
+				if idx >= 0 {
+					before := m.Snapshot(m.SelectedDate)
+					m.Tasks[idx].Tags = model.SplitTags(m.TextInput.Value())
+					m.SaveTasks()
+					m.Record(before, "set tags of %q", m.Tasks[idx].Title)
+				}
+				m.TextInput.SetValue("")
+				m.State = ViewState
+			case "esc":
+				m.TextInput.SetValue("")
+				m.State = ViewState
+			default:
+				m.TextInput, cmd = m.TextInput.Update(msg)
+				return m, cmd
+			}
+			m.ClampCursor()
+			return m, nil
+		}
+
+		// --- TAG FILTER MODE ---
+		if m.State == TagFilterState {
+			switch msg.String() {
+			case "enter":
+				m.TagFilter = model.SplitTags(m.FilterInput.Value())
+				m.State = ViewState
+			case "esc":
+				m.TagFilter = nil
+				m.FilterInput.SetValue("")
+				m.State = ViewState
+			default:
+				m.FilterInput, cmd = m.FilterInput.Update(msg)
+				return m, cmd
+			}
+			m.Page, m.Cursor = 0, 0
+			return m, nil
+		}
+
 		// --- SCRIPT INPUT MODE ---
 		if m.State == ScriptInputState {
 			switch msg.String() {
@@ -130,7 +401,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.ActiveScript.Description = val
 					
 					// Save
+					before := m.SnapshotScripts()
+					verb := "add"
 					if m.IsEditing {
+						verb = "edit"
 						idx := m.RealScriptIndex()
 						if idx >= 0 && idx < len(m.Scripts) {
 							m.Scripts[idx] = m.ActiveScript
@@ -139,7 +413,54 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.Scripts = append(m.Scripts, m.ActiveScript)
 					}
 					
-					repository.SaveScripts(m.Scripts)
+					m.SaveScripts()
+					m.RecordScripts(before, "%s script %q", verb, m.ActiveScript.Name)
+					m.State = ViewState
+					m.TextInput.SetValue("")
+				}
+				return m, nil
+			case "esc":
+				m.State = ViewState
+				m.TextInput.SetValue("")
+				return m, nil
+			default:
+				m.TextInput, cmd = m.TextInput.Update(msg)
+				return m, cmd
+			}
+		}
+
+		// --- LIST INPUT MODE ---
+		if m.State == ListInputState {
+			switch msg.String() {
+			case "enter":
+				val := strings.TrimSpace(m.TextInput.Value())
+				if val == "" {
+					return m, nil
+				}
+				switch m.ListInputStep {
+				case 0: // Name
+					if i := m.FilterIndex(val); i >= 0 && (!m.EditingList || i != m.ListCursor) {
+						m.Err = fmt.Errorf("a list named %q already exists", m.Filters[i].Name)
+						return m, nil
+					}
+					m.ActiveFilter.Name = val
+					m.ListInputStep++
+					m.TextInput.SetValue(m.ActiveFilter.Query)
+					m.TextInput.Placeholder = " e.g. tag:work status:open due:<tomorrow"
+				case 1: // Query
+					if _, err := query.Parse(val, time.Now()); err != nil {
+						m.Err = err
+						return m, nil
+					}
+					m.ActiveFilter.Query = val
+					if m.EditingList {
+						m.Filters[m.ListCursor] = m.ActiveFilter
+					} else {
+						m.Filters = append(m.Filters, m.ActiveFilter)
+						m.ListCursor = len(m.Filters) - 1
+					}
+					m.ListTaskIndex = 0
+					m.SaveFilters()
 					m.State = ViewState
 					m.TextInput.SetValue("")
 				}
@@ -155,23 +476,61 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// --- INPUT / EDIT MODE (Tasks) ---
+		if m.State == EditState && m.ActiveTab == ListTab {
+			switch msg.String() {
+			case "enter":
+				if title, tags := model.ParseTags(m.TextInput.Value()); title != "" {
+					m.UpdateListTask("edit", func(t *model.Task) {
+						t.Title, t.Tags = title, tags
+					})
+					m.TextInput.SetValue("")
+					m.State = ViewState
+				}
+			case "esc":
+				m.State = ViewState
+			default:
+				m.TextInput, cmd = m.TextInput.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
 		if m.State == InputState || m.State == EditState {
 			switch msg.String() {
 			case "enter":
-				if m.TextInput.Value() != "" {
+				title, tags := model.ParseTags(m.TextInput.Value())
+				if row, ok := m.SelectedRow(); ok && m.State == EditState && row.Sub >= 0 {
+					// Subtasks have no tags, keep the text as typed
+					if title := strings.TrimSpace(m.TextInput.Value()); title != "" {
+						before := m.Snapshot(m.SelectedDate)
+						idx := m.RealIndex()
+						old := m.Tasks[idx].Subtasks[row.Sub].Title
+						m.Tasks[idx].Subtasks[row.Sub].Title = title
+						m.SaveTasks()
+						m.Record(before, "edit subtask %q", old)
+						m.TextInput.SetValue("")
+						m.State = ViewState
+					}
+				} else if title != "" {
+					before := m.Snapshot(m.SelectedDate)
+					desc := fmt.Sprintf("add %q", title)
 					if m.State == EditState {
 						idx := m.RealIndex()
 						if idx >= 0 {
-							m.Tasks[idx].Title = m.TextInput.Value()
+							desc = fmt.Sprintf("edit %q", m.Tasks[idx].Title)
+							m.Tasks[idx].Title = title
+							m.Tasks[idx].Tags = tags
 						}
 					} else {
 						m.Tasks = append(m.Tasks, model.Task{
-							Title:     m.TextInput.Value(),
+							ID:        model.NewID(),
+							Title:     title,
+							Tags:      tags,
 							CreatedAt: time.Now(),
 						})
 					}
 					m.SortTasks()
-					repository.SaveTasks(m.SelectedDate, m.Tasks)
+					m.SaveTasks()
+					m.Record(before, "%s", desc)
 					m.TextInput.SetValue("")
 					m.State = ViewState
 				}
@@ -184,10 +543,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// --- UNDO / REDO ---
+		switch msg.String() {
+		case "u":
+			m.Undo()
+			return m, nil
+		case "ctrl+r":
+			m.Redo()
+			return m, nil
+		}
+
 		// --- TAB SPECIFIC LOGIC ---
-		if m.ActiveTab == TaskTab {
+		switch m.ActiveTab {
+		case TaskTab:
 			return m.updateTaskTab(msg)
-		} else {
+		case CalendarTab:
+			return m.updateCalendarTab(msg)
+		case ListTab:
+			return m.updateListTab(msg)
+		case TrashTab:
+			return m.updateTrashTab(msg)
+		default:
 			return m.updateScriptTab(msg)
 		}
 	}
@@ -197,6 +573,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m Model) updateScriptTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
+	case "/":
+		m.State = ScriptSearchState
+		m.SearchInput.SetValue(m.ScriptQuery)
+		m.SearchInput.Focus()
+		m.ScriptPage, m.ScriptCursor = 0, 0
+
+	case "esc":
+		m.ScriptQuery = ""
+		m.ScriptPage, m.ScriptCursor = 0, 0
+
 	case "up", "k":
 		if m.ScriptCursor > 0 {
 			m.ScriptCursor--
@@ -239,8 +625,16 @@ func (m Model) updateScriptTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.PagedScripts()) > 0 {
 			idx := m.RealScriptIndex()
 			if idx >= 0 && idx < len(m.Scripts) {
+				before := m.SnapshotScripts()
+				deleted := m.Scripts[idx]
+				if err := repository.Discard(m.Store, model.TrashScript(deleted, time.Now())); err != nil {
+					m.Err = err
+					break
+				}
 				m.Scripts = append(m.Scripts[:idx], m.Scripts[idx+1:]...)
-				repository.SaveScripts(m.Scripts)
+				m.SaveScripts()
+				m.RecordScripts(before, "delete script %q", deleted.Name)
+				m.Status = fmt.Sprintf("moved script %q to the trash • u: undo", deleted.Name)
 				m.ClampScriptCursor()
 			}
 		}
@@ -263,7 +657,7 @@ func (m Model) updateScriptTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.TextInput.SetValue("")
 				m.TextInput.Focus()
 			} else {
-				_ = script.Run(target.Command)
+				m.Err = script.Run(target.Command)
 			}
 		}
 
@@ -288,9 +682,148 @@ func (m *Model) ClampScriptCursor() {
 	}
 }
 
+// RealScriptIndex returns the position in m.Scripts of the script under the
+// cursor, or -1 if there is none.
 func (m Model) RealScriptIndex() int {
-	ps := m.PageSize()
-	return m.ScriptPage*ps + m.ScriptCursor
+	visible := m.VisibleScripts()
+	i := m.ScriptPage*m.PageSize() + m.ScriptCursor
+	if i < 0 || i >= len(visible) {
+		return -1
+	}
+	return visible[i].Index
+}
+
+func (m Model) updateCalendarTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "?":
+		m.State = HelpState
+	case "left", "h":
+		m.MoveCalendar(m.CalendarDate.AddDate(0, 0, -1))
+	case "right", "l":
+		m.MoveCalendar(m.CalendarDate.AddDate(0, 0, 1))
+	case "up", "k":
+		m.MoveCalendar(m.CalendarDate.AddDate(0, 0, -7))
+	case "down", "j":
+		m.MoveCalendar(m.CalendarDate.AddDate(0, 0, 7))
+	case "[":
+		m.MoveCalendar(shiftMonth(m.CalendarDate, -1))
+	case "]":
+		m.MoveCalendar(shiftMonth(m.CalendarDate, 1))
+	case "t":
+		m.MoveCalendar(time.Now())
+	case "enter":
+		m.ActiveTab = TaskTab
+		m.LoadDay(m.CalendarDate)
+	}
+	return m, nil
+}
+
+func (m Model) updateListTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "?":
+		m.State = HelpState
+
+	case "left", "h":
+		if m.ListCursor > 0 {
+			m.ListCursor--
+			m.ListTaskIndex = 0
+			m.LoadList()
+		}
+	case "right", "l":
+		if m.ListCursor < len(m.Filters)-1 {
+			m.ListCursor++
+			m.ListTaskIndex = 0
+			m.LoadList()
+		}
+	case "up", "k":
+		if m.ListTaskIndex > 0 {
+			m.ListTaskIndex--
+		}
+	case "down", "j":
+		if m.ListTaskIndex < len(m.ListTasks)-1 {
+			m.ListTaskIndex++
+		}
+
+	case "enter":
+		if dt, ok := m.SelectedListTask(); ok {
+			m.OpenResult(dt)
+		}
+	case " ":
+		m.UpdateListTask("toggle", func(t *model.Task) { t.SetCompleted(!t.Completed) })
+	case "+", "=":
+		m.UpdateListTask("raise priority of", func(t *model.Task) { t.Priority = t.Priority.Raise() })
+	case "-":
+		m.UpdateListTask("lower priority of", func(t *model.Task) { t.Priority = t.Priority.Lower() })
+	case "e":
+		if dt, ok := m.SelectedListTask(); ok {
+			m.State = EditState
+			m.TextInput.SetValue(dt.Task.TitleWithTags())
+			m.TextInput.Focus()
+		}
+	case "d":
+		m.DeleteListTask()
+
+	case "n", "E":
+		m.EditingList = msg.String() == "E"
+		if m.EditingList && len(m.Filters) == 0 {
+			break
+		}
+		m.ActiveFilter = model.Filter{}
+		if m.EditingList {
+			m.ActiveFilter = m.Filters[m.ListCursor]
+		}
+		m.State = ListInputState
+		m.ListInputStep = 0
+		m.TextInput.SetValue(m.ActiveFilter.Name)
+		m.TextInput.Placeholder = " List name..."
+		m.TextInput.Focus()
+	case "D":
+		if len(m.Filters) > 0 {
+			name := m.Filters[m.ListCursor].Name
+			m.Filters = append(m.Filters[:m.ListCursor], m.Filters[m.ListCursor+1:]...)
+			m.ListTaskIndex = 0
+			m.SaveFilters()
+			m.Status = fmt.Sprintf("deleted list %q", name)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateTrashTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "?":
+		m.State = HelpState
+	case "up", "k":
+		if m.TrashCursor > 0 {
+			m.TrashCursor--
+		}
+	case "down", "j":
+		if m.TrashCursor < len(m.TrashItems)-1 {
+			m.TrashCursor++
+		}
+	case "enter", "r":
+		m.RestoreTrash()
+	case "x", "X":
+		if len(m.TrashItems) > 0 {
+			m.State = PurgeState
+			m.PurgeAll = msg.String() == "X"
+		}
+	}
+	return m, nil
+}
+
+// shiftMonth moves d by n months, keeping the day of the month where the
+// target month has it and using its last day otherwise.
+func shiftMonth(d time.Time, n int) time.Time {
+	y, mon, day := d.Date()
+	last := time.Date(y, mon+time.Month(n)+1, 0, 0, 0, 0, 0, time.Local).Day()
+	return time.Date(y, mon+time.Month(n), min(day, last), 0, 0, 0, 0, time.Local)
 }
 
 func (m Model) updateTaskTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -307,6 +840,13 @@ func (m Model) updateTaskTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.DateInput.SetValue("")
 		m.DateInput.Focus()
 
+	case "G":
+		m.State = GlobalSearchState
+		m.SearchInput.SetValue("")
+		m.SearchInput.Placeholder = " Search all days..."
+		m.SearchInput.Focus()
+		m.SearchResults, m.ResultCursor, m.QueryErr = nil, 0, nil
+
 	case "/":
 		m.State = SearchState
 		m.SearchInput.Focus()
@@ -318,33 +858,51 @@ func (m Model) updateTaskTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		} else if m.Page > 0 {
 			m.Page--
 			m.Cursor = m.PageSize() - 1
+		} else if m.WeekView {
+			m.FocusWeekDay(-1)
 		}
 
 	case "down", "j":
-		if m.Cursor < len(m.PagedTasks())-1 {
+		if m.Cursor < len(m.PagedRows())-1 {
 			m.Cursor++
 		} else if m.Page < m.TotalPages()-1 {
 			m.Page++
 			m.Cursor = 0
+		} else if m.WeekView {
+			m.FocusWeekDay(1)
 		}
 
 	case "left", "h":
-		m.SelectedDate = m.SelectedDate.AddDate(0, 0, -1)
-		m.Tasks = repository.LoadTasks(m.SelectedDate)
-		m.SortTasks()
-		m.Page, m.Cursor = 0, 0
+		if m.WeekView {
+			m.LoadDay(m.SelectedDate.AddDate(0, 0, -7))
+		} else {
+			m.LoadDay(m.SelectedDate.AddDate(0, 0, -1))
+		}
 
 	case "right", "l":
-		m.SelectedDate = m.SelectedDate.AddDate(0, 0, 1)
-		m.Tasks = repository.LoadTasks(m.SelectedDate)
-		m.SortTasks()
-		m.Page, m.Cursor = 0, 0
+		if m.WeekView {
+			m.LoadDay(m.SelectedDate.AddDate(0, 0, 7))
+		} else {
+			m.LoadDay(m.SelectedDate.AddDate(0, 0, 1))
+		}
+
+	case "W":
+		m.WeekView = !m.WeekView
+		m.Week = nil
+		m.syncWeek()
+
+	case "enter":
+		if m.WeekView {
+			// Open the focused day on its own
+			m.WeekView = false
+			m.Week = nil
+		}
 
 	case "t":
-		m.SelectedDate = time.Now()
-		m.Tasks = repository.LoadTasks(m.SelectedDate)
-		m.SortTasks()
-		m.Page, m.Cursor = 0, 0
+		m.LoadDay(time.Now())
+
+	case "R":
+		m.Rollover()
 
 	case "n":
 		m.State = InputState
@@ -352,49 +910,432 @@ func (m Model) updateTaskTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.TextInput.Focus()
 
 	case "e":
-		if len(m.PagedTasks()) > 0 {
+		if row, ok := m.SelectedRow(); ok {
 			m.State = EditState
-			m.TextInput.SetValue(m.PagedTasks()[m.Cursor].Title)
+			if row.Sub >= 0 {
+				m.TextInput.SetValue(row.Task.Subtasks[row.Sub].Title)
+			} else {
+				m.TextInput.SetValue(row.Task.TitleWithTags())
+			}
 			m.TextInput.Focus()
 		}
 
+	case "#":
+		if row, ok := m.SelectedRow(); ok {
+			m.State = TagState
+			m.TextInput.SetValue(strings.Join(row.Task.Tags, " "))
+			m.TextInput.Focus()
+		}
+
+	case "r":
+		if row, ok := m.SelectedRow(); ok {
+			m.State = RecurState
+			m.TextInput.SetValue("")
+			if i := m.RecurrenceIndex(row.Task.RecurrenceID); i >= 0 {
+				m.TextInput.SetValue(m.Recurrences[i].Rule)
+			}
+			m.TextInput.Placeholder = " daily, weekdays, every 2 weeks on mon..."
+			m.TextInput.Focus()
+		}
+
+	case "m", "c":
+		if _, ok := m.SelectedRow(); ok {
+			m.State = ScheduleState
+			m.CopyTask = msg.String() == "c"
+			m.DateInput.SetValue("")
+			m.DateInput.Placeholder = " tomorrow, next fri, +3d, jan 5..."
+			m.DateInput.Focus()
+		}
+
+	case "@":
+		if row, ok := m.SelectedRow(); ok {
+			m.State = DueState
+			m.TextInput.SetValue(row.Task.Due)
+			m.TextInput.Placeholder = " HH:MM, 9am... (empty to clear)"
+			m.TextInput.Focus()
+		}
+
+	case "a":
+		if _, ok := m.SelectedRow(); ok {
+			m.State = SubtaskState
+			m.TextInput.SetValue("")
+			m.TextInput.Focus()
+		}
+
+	case "o":
+		if row, ok := m.SelectedRow(); ok && len(row.Task.Subtasks) > 0 {
+			m.Expanded[row.Task.ID] = !m.Expanded[row.Task.ID]
+			m.FocusTask(row.Task.ID)
+		}
+
+	case "f":
+		m.State = TagFilterState
+		m.FilterInput.SetValue(strings.Join(m.TagFilter, " "))
+		m.FilterInput.Focus()
+
+	case "N":
+		idx := m.RealIndex()
+		if idx >= 0 {
+			m.State = NotesState
+			m.NotesInput.SetValue(m.Tasks[idx].Notes)
+			m.NotesInput.Focus()
+			cmd = textarea.Blink
+		}
+
+	case "i":
+		m.ShowDetail = !m.ShowDetail
+
 	case " ":
+		row, ok := m.SelectedRow()
+		idx := m.RealIndex()
+		if ok && idx >= 0 {
+			before := m.Snapshot(m.SelectedDate)
+			if row.Sub >= 0 {
+				m.Tasks[idx].ToggleSubtask(row.Sub, m.Config.AutoCompleteParent)
+			} else {
+				m.Tasks[idx].SetCompleted(!m.Tasks[idx].Completed)
+			}
+			m.SortTasks()
+			m.SaveTasks()
+			if row.Sub >= 0 {
+				m.Record(before, "toggle subtask %q", row.Task.Subtasks[row.Sub].Title)
+			} else {
+				m.Record(before, "toggle %q", row.Task.Title)
+			}
+		}
+
+	case "+", "=", "-":
 		idx := m.RealIndex()
 		if idx >= 0 {
-			m.Tasks[idx].Completed = !m.Tasks[idx].Completed
+			before := m.Snapshot(m.SelectedDate)
+			t := m.Tasks[idx]
+			verb := "raise"
+			if msg.String() == "-" {
+				verb = "lower"
+				m.Tasks[idx].Priority = m.Tasks[idx].Priority.Lower()
+			} else {
+				m.Tasks[idx].Priority = m.Tasks[idx].Priority.Raise()
+			}
 			m.SortTasks()
-			repository.SaveTasks(m.SelectedDate, m.Tasks)
+			m.SaveTasks()
+			m.Record(before, "%s priority of %q", verb, t.Title)
+			m.FocusTask(t.ID)
 		}
 
 	case "d":
+		row, ok := m.SelectedRow()
 		idx := m.RealIndex()
-		if idx >= 0 {
-			m.Tasks = append(m.Tasks[:idx], m.Tasks[idx+1:]...)
-			repository.SaveTasks(m.SelectedDate, m.Tasks)
-			m.ClampCursor()
+		if ok && row.Sub >= 0 {
+			before := m.Snapshot(m.SelectedDate)
+			title := row.Task.Subtasks[row.Sub].Title
+			subs := m.Tasks[idx].Subtasks
+			m.Tasks[idx].Subtasks = append(subs[:row.Sub], subs[row.Sub+1:]...)
+			m.SaveTasks()
+			m.Record(before, "delete subtask %q", title)
+		} else if idx >= 0 {
+			m.DeleteTask()
 		}
 	}
 	m.ClampCursor()
 	return m, cmd
 }
 
+// LeaveState closes whatever prompt or edit is open, as esc would, so that
+// it cannot be completed later against another tab. Filters being typed are
+// kept, as enter would.
+func (m *Model) LeaveState() {
+	switch m.State {
+	case GlobalSearchState:
+		m.SearchInput.SetValue("")
+		m.SearchInput.Placeholder = " Search..."
+		m.SearchResults, m.QueryErr = nil, nil
+	case RolloverState:
+		m.PendingRollover = 0
+	case PurgeState:
+		m.PurgeAll = false
+	}
+	m.TextInput.SetValue("")
+	m.TextInput.Placeholder = " Description..."
+	m.DateInput.SetValue("")
+	m.DateInput.Placeholder = " today, -1w, jan 5, YYYY-MM-DD..."
+	m.TextInput.Blur()
+	m.DateInput.Blur()
+	m.SearchInput.Blur()
+	m.FilterInput.Blur()
+	m.NotesInput.Blur()
+	m.State = ViewState
+}
+
+// SearchAll fills SearchResults with the tasks of every day that contain
+// text or, when it is a query, match it. "@name" runs the saved filter of
+// that name. Errors are kept in QueryErr while the query is being typed.
+func (m *Model) SearchAll(text string) {
+	m.SearchResults, m.ResultCursor, m.QueryErr = nil, 0, nil
+	if name, ok := strings.CutPrefix(strings.TrimSpace(text), "@"); ok {
+		i := m.FilterIndex(name)
+		if i < 0 {
+			m.QueryErr = fmt.Errorf("no saved filter %q", name)
+			return
+		}
+		text = m.Filters[i].Query
+	}
+
+	expr, err := query.Parse(text, time.Now())
+	if err != nil && (strings.ContainsAny(text, ":()") || strings.HasPrefix(text, "-")) {
+		m.QueryErr = err
+		return
+	}
+	if err != nil || query.IsPlainText(expr) {
+		m.SearchResults, m.QueryErr = repository.Search(m.Store, text)
+		return
+	}
+	m.SearchResults, m.QueryErr = repository.Select(m.Store, func(dt repository.DatedTask) bool {
+		return expr.Match(dt.Task, dt.Date)
+	})
+}
+
+// FilterIndex returns the position of the saved filter with the given name
+// in m.Filters, ignoring case.
+func (m Model) FilterIndex(name string) int {
+	for i, f := range m.Filters {
+		if strings.EqualFold(f.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// FocusWeekDay moves the week view cursor to the next (dir 1) or previous
+// (dir -1) day of the week that has rows, landing on its first or last row.
+// At either end of the week the cursor stays where it is.
+func (m *Model) FocusWeekDay(dir int) {
+	for i := m.WeekDay() + dir; i >= 0 && i < len(m.Week); i += dir {
+		n := len(m.rowsOf(m.Week[i].Date, m.Week[i].Tasks))
+		if n == 0 {
+			continue
+		}
+		m.LoadDay(m.Week[i].Date)
+		if dir < 0 {
+			ps := m.PageSize()
+			m.Page, m.Cursor = (n-1)/ps, (n-1)%ps
+		}
+		return
+	}
+}
+
+// CheckReminders advances m.Now to now and raises a reminder for every
+// open task of today whose due time passed since the previous tick.
+func (m *Model) CheckReminders(now time.Time) {
+	prev := m.Now
+	m.Now = now
+
+	today := repository.Day(now)
+	tasks := m.Tasks
+	if !repository.Day(m.SelectedDate).Equal(today) {
+		var err error
+		if tasks, err = m.Store.LoadTasks(today); err != nil {
+			m.Err = err
+			return
+		}
+	}
+
+	var due []string
+	for _, t := range tasks {
+		at, ok := t.DueAt(today)
+		if ok && !t.Completed && at.After(prev) && !at.After(now) {
+			due = append(due, t.Title)
+		}
+	}
+	if len(due) > 0 {
+		m.Reminder = "due now: " + strings.Join(due, ", ")
+	}
+}
+
+// SetRecurrence makes the selected task repeat by the given rule, changes
+// the rule of an already recurring task, or stops it repeating if the rule
+// is empty. Instances already stored on other days are left as they are.
+// It reports whether anything changed; a rule that does not parse is left
+// in m.Err.
+func (m *Model) SetRecurrence(text string) bool {
+	idx := m.RealIndex()
+	if idx < 0 {
+		return false
+	}
+	t := &m.Tasks[idx]
+	r := m.RecurrenceIndex(t.RecurrenceID)
+
+	if text == "" {
+		if t.RecurrenceID == "" {
+			return false
+		}
+		if r >= 0 {
+			m.Recurrences = append(m.Recurrences[:r], m.Recurrences[r+1:]...)
+			m.SaveRecurrences()
+		}
+		t.RecurrenceID = ""
+		m.SaveTasks()
+		return true
+	}
+
+	rule, err := recur.Parse(text)
+	if err != nil {
+		m.Err = err
+		return false
+	}
+	if r >= 0 {
+		m.Recurrences[r].Rule = rule.String()
+	} else {
+		def := model.Recurrence{
+			ID:       model.NewID(),
+			Rule:     rule.String(),
+			Start:    m.SelectedDate.Format("2006-01-02"),
+			Title:    t.Title,
+			Priority: t.Priority,
+			Tags:     t.Tags,
+			Notes:    t.Notes,
+		}
+		m.Recurrences = append(m.Recurrences, def)
+		t.RecurrenceID = def.ID
+	}
+	m.SaveRecurrences()
+	if m.Err == nil {
+		m.SaveTasks()
+	}
+	if m.WeekView {
+		m.Reload() // instances on the other days changed too
+	}
+	m.Status = "repeats " + rule.Describe()
+	return true
+}
+
 // Helpers
 
 func (m Model) FilteredTasks() []model.Task {
+	return m.filterTasks(m.SelectedDate, m.Tasks)
+}
+
+// filterTasks applies the tag filter and, while searching, the search: a
+// structured query (see package query) or else fuzzy matching on the title,
+// best match first. An invalid query filters nothing.
+func (m Model) filterTasks(day time.Time, tasks []model.Task) []model.Task {
+	tasks = m.tagged(tasks)
 	if m.State != SearchState || m.SearchInput.Value() == "" {
-		return m.Tasks
+		return tasks
+	}
+
+	if expr, err := m.SearchExpr(); err != nil {
+		return tasks
+	} else if expr != nil {
+		var out []model.Task
+		for _, t := range tasks {
+			if expr.Match(t, day) {
+				out = append(out, t)
+			}
+		}
+		return out
 	}
 
-	q := strings.ToLower(m.SearchInput.Value())
+	titles := make([]string, len(tasks))
+	for i, t := range tasks {
+		titles[i] = t.Title
+	}
 	var out []model.Task
-	for _, t := range m.Tasks {
-		if strings.Contains(strings.ToLower(t.Title), q) {
+	for _, r := range fuzzy.Filter(m.SearchInput.Value(), titles) {
+		out = append(out, tasks[r.Index])
+	}
+	return out
+}
+
+// SearchExpr parses the search input as a query. Plain words give a nil
+// Expr, since they are matched fuzzily instead; text that only fails to
+// parse because it is not meant as a query is treated the same way.
+func (m Model) SearchExpr() (query.Expr, error) {
+	text := m.SearchInput.Value()
+	expr, err := query.Parse(text, time.Now())
+	if err != nil {
+		if strings.ContainsAny(text, ":()") || strings.HasPrefix(text, "-") {
+			return nil, err
+		}
+		return nil, nil
+	}
+	if query.IsPlainText(expr) {
+		return nil, nil
+	}
+	return expr, nil
+}
+
+// ScriptMatch is a script listed in the Scripts tab, with the positions of
+// the filter's match in its name or, failing that, its description.
+type ScriptMatch struct {
+	Index     int // position in m.Scripts
+	NameMatch []int
+	DescMatch []int
+}
+
+// VisibleScripts returns the scripts to list: all of them, or those matching
+// ScriptQuery, best match first. Matches in the name rank above matches
+// that are only in the description.
+func (m Model) VisibleScripts() []ScriptMatch {
+	var out []ScriptMatch
+	if m.ScriptQuery == "" {
+		for i := range m.Scripts {
+			out = append(out, ScriptMatch{Index: i})
+		}
+		return out
+	}
+
+	type scored struct {
+		ScriptMatch
+		name  bool
+		score int
+	}
+	var found []scored
+	for i, s := range m.Scripts {
+		if r, ok := fuzzy.Match(m.ScriptQuery, s.Name); ok {
+			found = append(found, scored{ScriptMatch{Index: i, NameMatch: r.Positions}, true, r.Score})
+		} else if r, ok := fuzzy.Match(m.ScriptQuery, s.Description); ok {
+			found = append(found, scored{ScriptMatch{Index: i, DescMatch: r.Positions}, false, r.Score})
+		}
+	}
+	sort.SliceStable(found, func(a, b int) bool {
+		if found[a].name != found[b].name {
+			return found[a].name
+		}
+		return found[a].score > found[b].score
+	})
+	for _, f := range found {
+		out = append(out, f.ScriptMatch)
+	}
+	return out
+}
+
+// TaggedTasks applies the tag filter, if any, to m.Tasks.
+func (m Model) TaggedTasks() []model.Task {
+	return m.tagged(m.Tasks)
+}
+
+func (m Model) tagged(tasks []model.Task) []model.Task {
+	if len(m.TagFilter) == 0 {
+		return tasks
+	}
+	var out []model.Task
+	for _, t := range tasks {
+		if hasAllTags(t, m.TagFilter) {
 			out = append(out, t)
 		}
 	}
 	return out
 }
 
+func hasAllTags(t model.Task, tags []string) bool {
+	for _, tag := range tags {
+		if !t.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
 func (m Model) PageSize() int {
 	ps := m.Height - 10
 	if ps < 1 {
@@ -403,8 +1344,33 @@ func (m Model) PageSize() int {
 	return ps
 }
 
+// Row is a line in the task list: a task, or one of its subtasks when the
+// task is expanded.
+type Row struct {
+	Task model.Task
+	Sub  int // index into Task.Subtasks, -1 for the task itself
+}
+
+// VisibleRows flattens the filtered tasks and their expanded subtasks.
+func (m Model) VisibleRows() []Row {
+	return m.rowsOf(m.SelectedDate, m.Tasks)
+}
+
+func (m Model) rowsOf(day time.Time, tasks []model.Task) []Row {
+	var rows []Row
+	for _, t := range m.filterTasks(day, tasks) {
+		rows = append(rows, Row{Task: t, Sub: -1})
+		if m.Expanded[t.ID] {
+			for i := range t.Subtasks {
+				rows = append(rows, Row{Task: t, Sub: i})
+			}
+		}
+	}
+	return rows
+}
+
 func (m Model) TotalPages() int {
-	items := len(m.FilteredTasks())
+	items := len(m.VisibleRows())
 	ps := m.PageSize()
 	if items == 0 {
 		return 1
@@ -412,9 +1378,9 @@ func (m Model) TotalPages() int {
 	return (items + ps - 1) / ps
 }
 
-func (m Model) PagedTasks() []model.Task {
+func (m Model) PagedRows() []Row {
 	ps := m.PageSize()
-	items := m.FilteredTasks()
+	items := m.VisibleRows()
 
 	start := m.Page * ps
 	end := start + ps
@@ -429,7 +1395,7 @@ func (m Model) PagedTasks() []model.Task {
 }
 
 func (m *Model) ClampCursor() {
-	pageTasks := m.PagedTasks()
+	pageTasks := m.PagedRows()
 	if len(pageTasks) == 0 {
 		m.Cursor = 0
 		return
@@ -442,14 +1408,41 @@ func (m *Model) ClampCursor() {
 	}
 }
 
-func (m Model) RealIndex() int {
-	paged := m.PagedTasks()
+// SelectedRow returns the row under the cursor.
+func (m Model) SelectedRow() (Row, bool) {
+	paged := m.PagedRows()
 	if len(paged) == 0 || m.Cursor >= len(paged) {
+		return Row{}, false
+	}
+	return paged[m.Cursor], true
+}
+
+// RealIndex returns the position in m.Tasks of the task under the cursor,
+// or of the parent when the cursor is on a subtask.
+func (m Model) RealIndex() int {
+	row, ok := m.SelectedRow()
+	if !ok {
 		return -1
 	}
-	target := paged[m.Cursor]
+	return m.IndexOf(row.Task.ID)
+}
+
+// FocusTask moves the cursor onto the task with the given ID, e.g. after a
+// re-sort moved it.
+func (m *Model) FocusTask(id string) {
+	ps := m.PageSize()
+	for i, row := range m.VisibleRows() {
+		if row.Sub < 0 && row.Task.ID == id {
+			m.Page, m.Cursor = i/ps, i%ps
+			return
+		}
+	}
+}
+
+// IndexOf returns the position of the task with the given ID in m.Tasks.
+func (m Model) IndexOf(id string) int {
 	for i, t := range m.Tasks {
-		if t.CreatedAt.Equal(target.CreatedAt) && t.Title == target.Title {
+		if t.ID == id {
 			return i
 		}
 	}
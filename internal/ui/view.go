@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"zenith/internal/fuzzy"
 	"zenith/internal/model"
+	"zenith/internal/recur"
+	"zenith/internal/repository"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -12,14 +15,32 @@ import (
 func (m Model) HelpView() string {
 	keys := [][]string{
 		{"j/k", "move cursor up/down"},
-		{"h/l", "previous/next day"},
+		{"h/l", "previous/next day (week in week view)"},
+		{"W", "toggle week view"},
+		{"enter", "open the focused day (week view)"},
 		{"t", "jump to today"},
-		{"n", "new task/script"},
+		{"R", "carry unfinished tasks over to today"},
+		{"n", "new task/script (#tag adds a tag)"},
 		{"e", "edit task/script"},
 		{"space", "toggle complet"},
 		{"d", "delete task/script"},
-		{"/", "search task"},
-		{"g", "go to date yyyy-mm-dd"},
+		{"+/-", "raise/lower priority"},
+		{"u/ctrl+r", "undo/redo the last change"},
+		{"m/c", "move/copy task to another day"},
+		{"/", "search tasks/scripts (fuzzy, or a query like tag:work p:>=high)"},
+		{"G", "search all days (text, query or @saved-filter)"},
+		{"#", "set task tags"},
+		{"@", "set due time"},
+		{"r", "set repeat rule (empty to stop)"},
+		{"a", "add subtask"},
+		{"o", "expand/collapse subtasks"},
+		{"N", "edit task notes"},
+		{"i", "toggle detail pane"},
+		{"f", "filter by tags"},
+		{"g", "go to date (tomorrow, next fri, +3d, jan 5...)"},
+		{"[/]", "previous/next month (calendar)"},
+		{"n/E/D", "new/edit/delete saved list (lists)"},
+		{"r/x/X", "restore/purge item, empty trash (trash)"},
 		{"q", "quit"},
 	}
 
@@ -43,6 +64,17 @@ func (m Model) HelpView() string {
 	return s.String()
 }
 
+// upcomingWindow is how far ahead a due time is highlighted as upcoming.
+const upcomingWindow = time.Hour
+
+// plural formats a count with its noun, e.g. "1 task" or "3 tasks".
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
 func startOfDay(t time.Time) time.Time {
 	year, month, day := t.Date()
 	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
@@ -63,95 +95,649 @@ func (m Model) View() string {
 	// Tabs
 	taskTabStyle := lipgloss.NewStyle().Padding(0, 1)
 	scriptTabStyle := lipgloss.NewStyle().Padding(0, 1)
-
-	if m.ActiveTab == TaskTab {
-		taskTabStyle = scriptTabStyle.Foreground(AccentColor).Bold(true)
-	} else {
+	calendarTabStyle := lipgloss.NewStyle().Padding(0, 1)
+	listTabStyle := lipgloss.NewStyle().Padding(0, 1)
+	trashTabStyle := lipgloss.NewStyle().Padding(0, 1)
+
+	switch m.ActiveTab {
+	case TaskTab:
+		taskTabStyle = taskTabStyle.Foreground(AccentColor).Bold(true)
+	case ScriptTab:
 		scriptTabStyle = scriptTabStyle.Foreground(AccentColor).Bold(true)
+	case CalendarTab:
+		calendarTabStyle = calendarTabStyle.Foreground(AccentColor).Bold(true)
+	case ListTab:
+		listTabStyle = listTabStyle.Foreground(AccentColor).Bold(true)
+	case TrashTab:
+		trashTabStyle = trashTabStyle.Foreground(AccentColor).Bold(true)
 	}
 
 	tabs := lipgloss.JoinHorizontal(lipgloss.Bottom,
 		taskTabStyle.Render(" Tasks "),
 		scriptTabStyle.Render(" Scripts "),
+		calendarTabStyle.Render(" Calendar "),
+		listTabStyle.Render(" Lists "),
+		trashTabStyle.Render(" Trash "),
 	)
 
 	topBar := lipgloss.JoinHorizontal(lipgloss.Center, header, "  ", tabs)
+	if m.Reminder != "" {
+		topBar = lipgloss.JoinHorizontal(lipgloss.Center, topBar, "  ", ReminderStyle.Render(" ⏰ "+m.Reminder+" "))
+	}
 
 	// --- Main Content ---
 	var content string
 	var footer string
 
-	if m.ActiveTab == TaskTab {
+	if m.ActiveTab == TaskTab && m.State == GlobalSearchState {
+		content = m.viewSearchResults()
+		footer = m.viewTaskFooter()
+	} else if m.ActiveTab == TaskTab {
 		content = m.viewTasks()
+		if m.ShowDetail || m.State == NotesState {
+			list := lipgloss.NewStyle().Width(m.InnerWidth() - m.DetailWidth() - 1).Render(content)
+			content = lipgloss.JoinHorizontal(lipgloss.Top, list, m.viewDetail())
+		}
 		footer = m.viewTaskFooter()
+	} else if m.ActiveTab == CalendarTab {
+		content = m.viewCalendar()
+		footer = FooterTextStyle.Render("\n h/l: day • j/k: week • [/]: month • t: today • enter: open day • tab: switch")
+	} else if m.ActiveTab == ListTab {
+		content = m.viewLists()
+		footer = m.viewListFooter()
+	} else if m.ActiveTab == TrashTab {
+		content = m.viewTrash()
+		footer = m.viewTrashFooter()
 	} else {
 		content = m.viewScripts()
 		footer = m.viewScriptFooter()
 	}
 
+	if m.Err != nil && m.State == ViewState {
+		footer = "\n " + ErrorStyle.Render("error: "+m.Err.Error())
+	} else if m.Status != "" && m.State == ViewState {
+		footer = "\n " + StatusStyle.Render(m.Status)
+	}
+
 	main := topBar + "\n" + content + footer
 	return m.Center(main)
 }
 
 func (m Model) viewTasks() string {
+	if m.WeekView {
+		return m.viewWeek()
+	}
+
 	var list strings.Builder
 	list.WriteString("\n")
 
 	dateInfo := DateStyle.Render(" " + m.SelectedDate.Format("Monday, 02 Jan 2006"))
 	list.WriteString(dateInfo + "\n\n")
 
+	paged := m.PagedRows()
+	for i, r := range paged {
+		list.WriteString(m.viewRow(r, m.SelectedDate, i == m.Cursor) + "\n")
+	}
+
+	// Pad empty space
+	for i := len(paged); i < m.PageSize(); i++ {
+		list.WriteString("\n")
+	}
+	return list.String()
+}
+
+// viewWeek stacks the seven days of the week under their headings. When
+// they do not fit, the lines are scrolled to keep the cursor in view.
+func (m Model) viewWeek() string {
+	var lines []string
+	cursorLine := 0
 	today := startOfDay(time.Now())
-	selected := startOfDay(m.SelectedDate)
 
-	paged := m.PagedTasks()
-	for i, t := range paged {
+	for i, day := range m.Week {
+		rows := m.rowsOf(day.Date, day.Tasks)
+		focused := i == m.WeekDay()
+
+		heading := GrayTextStyle
+		if focused {
+			heading = DateStyle
+		}
+		title := " " + day.Date.Format("Mon 02 Jan")
+		if day.Date.Equal(today) {
+			title += " · today"
+		}
+		done := 0
+		for _, t := range day.Tasks {
+			if t.Completed {
+				done++
+			}
+		}
+		count := ""
+		if len(day.Tasks) > 0 {
+			count = " " + GrayTextStyle.Render(fmt.Sprintf("%d/%d", done, len(day.Tasks)))
+		}
+		lines = append(lines, heading.Render(title)+count)
+
+		if focused && len(rows) == 0 {
+			cursorLine = len(lines) - 1
+		}
+		for j, r := range rows {
+			selected := focused && j == m.Page*m.PageSize()+m.Cursor
+			if selected {
+				cursorLine = len(lines)
+			}
+			lines = append(lines, m.viewRow(r, day.Date, selected))
+		}
+	}
+
+	height := m.PageSize() + 1 // as tall as the day view
+	start := 0
+	if len(lines) > height {
+		start = cursorLine - height/2
+		if start < 0 {
+			start = 0
+		}
+		if start > len(lines)-height {
+			start = len(lines) - height
+		}
+		lines = lines[start : start+height]
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+
+	week := DateStyle.Render(" Week of " + m.Week[0].Date.Format("02 Jan 2006"))
+	return "\n" + week + "\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// viewSearchResults lists the matches of a global search with their days,
+// scrolled to keep the cursor in view.
+func (m Model) viewSearchResults() string {
+	var b strings.Builder
+	b.WriteString("\n")
+	switch {
+	case m.SearchInput.Value() == "":
+		b.WriteString(DateStyle.Render(" Search every day") + "\n\n")
+	default:
+		b.WriteString(DateStyle.Render(" "+plural(len(m.SearchResults), "result")) + "\n\n")
+	}
+
+	ps := m.PageSize()
+	start := 0
+	if m.ResultCursor >= ps {
+		start = m.ResultCursor - ps + 1
+	}
+	end := min(start+ps, len(m.SearchResults))
+	for i := start; i < end; i++ {
+		b.WriteString(viewDatedTask(m.SearchResults[i], i == m.ResultCursor) + "\n")
+	}
+	for i := end - start; i < ps; i++ {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// viewDatedTask renders a task listed with its day, as in search results
+// and saved lists.
+func viewDatedTask(r repository.DatedTask, selected bool) string {
+	cur := " "
+	if selected {
+		cur = lipgloss.NewStyle().Foreground(AccentColor).Render("❯")
+	}
+	icon := "[ ]"
+	style := lipgloss.NewStyle()
+	if r.Task.Completed {
+		icon = "[x]"
+		style = style.Foreground(GrayColor).Strikethrough(true)
+	}
+	prio := ""
+	if marker, ok := PriorityMarkers[r.Task.Priority]; ok && !r.Task.Completed {
+		prio = PriorityStyles[r.Task.Priority].Render(marker)
+	}
+	chips := ""
+	for _, tag := range r.Task.Tags {
+		chips += " " + TagStyle.Render("#"+tag)
+	}
+	return lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		CursorCol.Render(cur),
+		GrayTextStyle.Render(r.Date.Format("Mon 02 Jan 2006")+"  "),
+		PriorityCol.Render(prio),
+		style.Render(icon+" "+r.Task.Title),
+		chips,
+	)
+}
+
+// viewTrash lists deleted tasks and scripts, newest first, with when they
+// were deleted and where from.
+func (m Model) viewTrash() string {
+	var b strings.Builder
+	b.WriteString("\n")
+	header := " Trash is empty"
+	if len(m.TrashItems) > 0 {
+		header = " " + plural(len(m.TrashItems), "deleted item")
+	}
+	if m.Config.TrashRetentionDays > 0 {
+		header += fmt.Sprintf(", kept for %d days", m.Config.TrashRetentionDays)
+	}
+	b.WriteString(DateStyle.Render(header) + "\n\n")
+
+	ps := m.PageSize()
+	start := 0
+	if m.TrashCursor >= ps {
+		start = m.TrashCursor - ps + 1
+	}
+	end := min(start+ps, len(m.TrashItems))
+	for i := start; i < end; i++ {
+		item := m.TrashItems[i]
 		cur := " "
-		if i == m.Cursor && m.State == ViewState && m.ActiveTab == TaskTab {
+		if i == m.TrashCursor {
 			cur = lipgloss.NewStyle().Foreground(AccentColor).Render("❯")
 		}
+		kind, from := "task  ", ""
+		if item.Script != nil {
+			kind = "script"
+		} else if d, err := time.ParseInLocation("2006-01-02", item.Date, time.Local); err == nil {
+			from = "  from " + d.Format("Mon 02 Jan 2006")
+		}
+		b.WriteString(lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			CursorCol.Render(cur),
+			GrayTextStyle.Render(item.DeletedAt.Format("02 Jan 15:04")+"  "+kind+"  "),
+			lipgloss.NewStyle().Bold(i == m.TrashCursor).Render(item.Title()),
+			GrayTextStyle.Render(from),
+		) + "\n")
+	}
+	for i := end - start; i < ps; i++ {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
 
-		icon := "[ ]"
-		if t.Completed {
-			icon = "[x]"
+func (m Model) viewTrashFooter() string {
+	if m.State == PurgeState {
+		what := "everything in the trash"
+		if !m.PurgeAll && m.TrashCursor < len(m.TrashItems) {
+			what = fmt.Sprintf("%q", m.TrashItems[m.TrashCursor].Title())
 		}
+		return "\n " + ErrorStyle.Render("Delete "+what+" for good? (y/n)")
+	}
+	return FooterTextStyle.Render("\n enter/r: restore • x: purge • X: empty trash • tab: switch")
+}
 
+// listsWidth is the width of the column of list names in the Lists tab.
+const listsWidth = 24
+
+// viewLists shows the saved lists next to the tasks of the selected one,
+// scrolled to keep the cursor in view.
+func (m Model) viewLists() string {
+	var names strings.Builder
+	names.WriteString(lipgloss.NewStyle().Bold(true).Foreground(AccentColor).Render(" Lists") + "\n\n")
+	for i, f := range m.Filters {
+		cur := " "
 		style := lipgloss.NewStyle()
+		if i == m.ListCursor {
+			cur = lipgloss.NewStyle().Foreground(AccentColor).Render("❯")
+			style = style.Bold(true).Foreground(AccentColor)
+		}
+		names.WriteString(CursorCol.Render(cur) + style.MaxWidth(listsWidth-3).Render(f.Name) + "\n")
+	}
+	if len(m.Filters) == 0 {
+		names.WriteString(GrayTextStyle.Render(" No lists. Press n.") + "\n")
+	}
+
+	var tasks strings.Builder
+	if len(m.Filters) > 0 {
+		f := m.Filters[m.ListCursor]
+		tasks.WriteString(DateStyle.Render(" "+plural(len(m.ListTasks), "task")) + GrayTextStyle.Render("  "+f.Query) + "\n\n")
+	}
+	ps := m.PageSize()
+	start := 0
+	if m.ListTaskIndex >= ps {
+		start = m.ListTaskIndex - ps + 1
+	}
+	end := min(start+ps, len(m.ListTasks))
+	for i := start; i < end; i++ {
+		tasks.WriteString(viewDatedTask(m.ListTasks[i], i == m.ListTaskIndex) + "\n")
+	}
+	for i := end - start; i < ps; i++ {
+		tasks.WriteString("\n")
+	}
+
+	return "\n" + lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(listsWidth).Render(names.String()),
+		tasks.String(),
+	)
+}
+
+func (m Model) viewListFooter() string {
+	switch m.State {
+	case ListInputState:
+		label := "LIST NAME:"
+		if m.ListInputStep == 1 {
+			label = "QUERY:"
+		}
+		footer := "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render(label) + " " + m.TextInput.View()
+		if m.Err != nil {
+			footer += " " + ErrorStyle.Render(m.Err.Error())
+		}
+		return footer
+	case EditState:
+		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("EDIT:") + " " + m.TextInput.View()
+	default:
+		return FooterTextStyle.Render("\n h/l: list • space: toggle • e: edit • d: delete • enter: open day • n/E/D: lists • tab: switch")
+	}
+}
+
+// calendarCellWidth fits a day number and a done/total count.
+const calendarCellWidth = 9
+
+// viewCalendar draws CalendarDate's month as a Monday-first grid. Every day
+// shows its done/total count, colored by how much of it is done.
+func (m Model) viewCalendar() string {
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(DateStyle.Render(" "+m.CalendarDate.Format("January 2006")) + "\n\n")
+
+	cell := lipgloss.NewStyle().Width(calendarCellWidth).Align(lipgloss.Center)
+	var header []string
+	for _, name := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+		header = append(header, HelpKeyStyle.Inherit(cell).Render(name))
+	}
+	b.WriteString(" " + lipgloss.JoinHorizontal(lipgloss.Top, header...) + "\n")
+
+	y, mon, _ := m.CalendarDate.Date()
+	first := time.Date(y, mon, 1, 0, 0, 0, 0, time.Local)
+	offset := (int(first.Weekday()) + 6) % 7
+	today := startOfDay(time.Now())
+
+	lines := 2
+	var week []string
+	for i := 0; i < offset+len(m.MonthCounts); i++ {
+		if i < offset {
+			week = append(week, cell.Render(""))
+		} else {
+			day := i - offset + 1
+			d := time.Date(y, mon, day, 0, 0, 0, 0, time.Local)
+			week = append(week, m.viewCalendarDay(cell, d, m.MonthCounts[day-1], d.Equal(today)))
+		}
+		if len(week) == 7 || i == offset+len(m.MonthCounts)-1 {
+			b.WriteString(" " + lipgloss.JoinHorizontal(lipgloss.Top, week...) + "\n\n")
+			week = nil
+			lines += 3
+		}
+	}
+
+	legend := GrayTextStyle.Render("done: ")
+	for i, style := range HeatStyles {
+		legend += style.Render(fmt.Sprintf(" %d%% ", i*100/(len(HeatStyles)-1)))
+	}
+	b.WriteString("\n " + legend + "\n")
+	for i := lines + 2; i < m.PageSize()+1; i++ {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// viewCalendarDay renders the two lines of a day: its number, highlighted
+// under the cursor, and its heat colored count.
+func (m Model) viewCalendarDay(cell lipgloss.Style, d time.Time, c DayCount, today bool) string {
+	num := fmt.Sprintf(" %d ", d.Day())
+	if today {
+		num = fmt.Sprintf(" %d• ", d.Day())
+	}
+	numStyle := GrayTextStyle
+	if today {
+		numStyle = DateStyle
+	}
+	if d.Equal(m.CalendarDate) {
+		numStyle = HeaderStyle.Padding(0)
+	}
+
+	count := ""
+	if c.Total > 0 {
+		heat := HeatStyles[c.Done*(len(HeatStyles)-1)/c.Total]
+		count = heat.Render(fmt.Sprintf(" %d/%d ", c.Done, c.Total))
+	}
+	return cell.Render(numStyle.Render(num) + "\n" + count)
+}
+
+// viewRow renders a task list row of day, with the cursor if selected.
+func (m Model) viewRow(r Row, day time.Time, selected bool) string {
+	cur := " "
+	if selected && m.State == ViewState && m.ActiveTab == TaskTab {
+		cur = lipgloss.NewStyle().Foreground(AccentColor).Render("❯")
+	}
+
+	if r.Sub >= 0 {
+		return m.viewSubtask(cur, r.Task.Subtasks[r.Sub])
+	}
+	t := r.Task
+
+	icon := "[ ]"
+	if t.Completed {
+		icon = "[x]"
+	}
+
+	style := lipgloss.NewStyle()
+	if t.Completed {
+		style = style.Foreground(GrayColor).Strikethrough(true)
+	} else if startOfDay(day).Before(startOfDay(time.Now())) {
+		style = style.Foreground(RedColor).Bold(true)
+	}
+
+	prio := ""
+	if marker, ok := PriorityMarkers[t.Priority]; ok {
+		prio = marker
+		if !t.Completed {
+			prio = PriorityStyles[t.Priority].Render(marker)
+		}
+	}
+
+	chips := ""
+	for _, tag := range t.Tags {
+		chip := TagStyle
 		if t.Completed {
-			style = style.Foreground(GrayColor).Strikethrough(true)
-		} else if selected.Before(today) {
-			style = style.Foreground(RedColor).Bold(true)
+			chip = chip.Foreground(GrayColor)
 		}
+		chips += " " + chip.Render("#"+tag)
+	}
 
-		titleWithIcon := icon + " " + t.Title;
-		row := lipgloss.JoinHorizontal(
-			lipgloss.Left,
-			CursorCol.Render(cur),
-			// CheckCol.Render(icon),
-			style.Render(titleWithIcon),
-		)
+	due := ""
+	if at, ok := t.DueAt(day); ok {
+		dueStyle := GrayTextStyle
+		switch {
+		case t.Completed:
+		case at.Before(m.Now):
+			dueStyle = ErrorStyle
+		case at.Sub(m.Now) <= upcomingWindow:
+			dueStyle = UpcomingStyle
+		}
+		due = dueStyle.Render(t.Due) + " "
+	}
 
-		list.WriteString(row + "\n")
+	carried := ""
+	if t.CarriedTo != "" {
+		style = lipgloss.NewStyle().Foreground(GrayColor).Italic(true)
+		carried = " " + GrayTextStyle.Render("→ "+t.CarriedTo)
+	} else if t.CarryCount > 0 {
+		carried = " " + GrayTextStyle.Render(fmt.Sprintf("↻%d", t.CarryCount))
 	}
 
-	// Pad empty space
-	for i := len(paged); i < m.PageSize(); i++ {
-		list.WriteString("\n")
+	if t.RecurrenceID != "" {
+		carried += " " + GrayTextStyle.Render("⟳")
 	}
-	return list.String()
+
+	progress := ""
+	if done, total := t.Progress(); total > 0 {
+		fold := "▸"
+		if m.Expanded[t.ID] {
+			fold = "▾"
+		}
+		progress = " " + GrayTextStyle.Render(fmt.Sprintf("%s %d/%d", fold, done, total))
+	}
+
+	titleWithIcon := style.Render(icon + " " + due + t.Title)
+	if expr, err := m.SearchExpr(); m.State == SearchState && m.SearchInput.Value() != "" && expr == nil && err == nil {
+		if match, ok := fuzzy.Match(m.SearchInput.Value(), t.Title); ok {
+			titleWithIcon = style.Render(icon+" ") + due + highlight(t.Title, match.Positions, style)
+		}
+	}
+	return lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		CursorCol.Render(cur),
+		PriorityCol.Render(prio),
+		// CheckCol.Render(icon),
+		titleWithIcon,
+		progress,
+		carried,
+		chips,
+	)
+}
+
+// highlight renders text in base with the runes at positions, as returned
+// by fuzzy.Match, picked out in MatchStyle.
+func highlight(text string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(text)
+	}
+	match := MatchStyle.Inherit(base)
+	var b strings.Builder
+	var run []rune
+	matched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if matched {
+			b.WriteString(match.Render(string(run)))
+		} else {
+			b.WriteString(base.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	p := 0
+	for i, r := range []rune(text) {
+		isMatch := p < len(positions) && positions[p] == i
+		if isMatch {
+			p++
+		}
+		if isMatch != matched {
+			flush()
+			matched = isMatch
+		}
+		run = append(run, r)
+	}
+	flush()
+	return b.String()
+}
+
+// viewSubtask renders a checklist item indented under its task.
+func (m Model) viewSubtask(cur string, s model.Subtask) string {
+	icon := "[ ]"
+	style := lipgloss.NewStyle()
+	if s.Completed {
+		icon = "[x]"
+		style = style.Foreground(GrayColor).Strikethrough(true)
+	}
+	return lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		CursorCol.Render(cur),
+		PriorityCol.Render(""),
+		"    ",
+		style.Render(icon+" "+s.Title),
+	)
+}
+
+// InnerWidth is the width available to content inside the frame drawn by Center.
+func (m Model) InnerWidth() int {
+	return m.Width - 12
+}
+
+// DetailWidth is the width of the detail pane, including its padding.
+func (m Model) DetailWidth() int {
+	w := m.InnerWidth() * 2 / 5
+	if w < 24 {
+		return 24
+	}
+	return w
 }
 
-func (m Model) PagedScripts() []model.Script {
+func (m Model) viewDetail() string {
+	var b strings.Builder
+	b.WriteString("\n")
+
+	row, ok := m.SelectedRow()
+	if !ok {
+		b.WriteString(GrayTextStyle.Render("No task selected"))
+		return DetailStyle.Width(m.DetailWidth()).Render(b.String())
+	}
+	t := row.Task
+	w := m.DetailWidth() - 2
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Width(w).Render(t.Title) + "\n\n")
+
+	field := func(label, value string) {
+		b.WriteString(HelpKeyStyle.Width(11).Render(label) + HelpValueStyle.Render(value) + "\n")
+	}
+	status := "open"
+	if t.Completed {
+		status = "done"
+	}
+	field("status", status)
+	field("priority", t.Priority.String())
+	if len(t.Tags) > 0 {
+		field("tags", "#"+strings.Join(t.Tags, " #"))
+	}
+	if done, total := t.Progress(); total > 0 {
+		field("subtasks", fmt.Sprintf("%d/%d done", done, total))
+	}
+	if t.Due != "" {
+		field("due", t.Due)
+	}
+	if !t.CreatedAt.IsZero() {
+		field("created", t.CreatedAt.Format("02 Jan 2006 15:04"))
+	}
+	if i := m.RecurrenceIndex(t.RecurrenceID); i >= 0 {
+		if rule, err := recur.Parse(m.Recurrences[i].Rule); err == nil {
+			field("repeats", rule.Describe())
+		}
+	}
+	if t.CarriedFrom != "" {
+		field("carried", fmt.Sprintf("from %s, %d×", t.CarriedFrom, t.CarryCount))
+	}
+	if t.CarriedTo != "" {
+		field("carried to", t.CarriedTo)
+	}
+	if t.CompletedAt != nil {
+		field("completed", t.CompletedAt.Format("02 Jan 2006 15:04"))
+	}
+
+	b.WriteString("\n")
+	switch {
+	case m.State == NotesState:
+		b.WriteString(m.NotesInput.View())
+	case t.Notes == "":
+		b.WriteString(GrayTextStyle.Render("No notes. Press N to add some."))
+	default:
+		b.WriteString(lipgloss.NewStyle().Width(w).Render(t.Notes))
+	}
+
+	return DetailStyle.Width(m.DetailWidth()).Render(b.String())
+}
+
+func (m Model) PagedScripts() []ScriptMatch {
 	ps := m.PageSize()
+	visible := m.VisibleScripts()
 	start := m.ScriptPage * ps
 	end := start + ps
 
-	if start >= len(m.Scripts) {
+	if start >= len(visible) {
 		return nil
 	}
-	if end > len(m.Scripts) {
-		end = len(m.Scripts)
+	if end > len(visible) {
+		end = len(visible)
 	}
-	return m.Scripts[start:end]
+	return visible[start:end]
 }
 
 func (m Model) viewScripts() string {
@@ -160,17 +746,20 @@ func (m Model) viewScripts() string {
 	list.WriteString(lipgloss.NewStyle().Bold(true).Foreground(AccentColor).Render(" Automation Scripts") + "\n\n")
 
 	paged := m.PagedScripts()
-	for i, s := range paged {
+	for i, match := range paged {
+		s := m.Scripts[match.Index]
 		cur := " "
-		if i == m.ScriptCursor && m.State == ViewState && m.ActiveTab == ScriptTab {
+		if i == m.ScriptCursor && (m.State == ViewState || m.State == ScriptSearchState) && m.ActiveTab == ScriptTab {
 			cur = lipgloss.NewStyle().Foreground(AccentColor).Render("❯")
 		}
 
+		nameStyle := lipgloss.NewStyle().Bold(true)
+		descStyle := lipgloss.NewStyle().Foreground(GrayColor)
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Left,
 			CursorCol.Render(cur),
-			lipgloss.NewStyle().Width(20).Bold(true).Render(s.Name),
-			lipgloss.NewStyle().Foreground(GrayColor).Render(s.Description),
+			lipgloss.NewStyle().Width(20).Render(highlight(s.Name, match.NameMatch, nameStyle)),
+			highlight(s.Description, match.DescMatch, descStyle),
 		)
 		list.WriteString(row + "\n")
 	}
@@ -186,7 +775,17 @@ func (m Model) viewScripts() string {
 func (m Model) viewTaskFooter() string {
 	switch m.State {
 	case SearchState:
-		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("SEARCH:") + " " + m.SearchInput.View()
+		footer := "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("SEARCH:") + " " + m.SearchInput.View()
+		if _, err := m.SearchExpr(); err != nil {
+			footer += " " + ErrorStyle.Render(err.Error())
+		}
+		return footer
+	case GlobalSearchState:
+		footer := "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("SEARCH ALL:") + " " + m.SearchInput.View()
+		if m.QueryErr != nil {
+			footer += " " + ErrorStyle.Render(m.QueryErr.Error())
+		}
+		return footer
 	case InputState, EditState:
 		label := "NEW:"
 		if m.State == EditState {
@@ -195,14 +794,39 @@ func (m Model) viewTaskFooter() string {
 		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render(label) + " " + m.TextInput.View()
 	case GotoDateState:
 		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("GO TO DATE:") + " " + m.DateInput.View()
+	case ScheduleState:
+		label := "MOVE TO:"
+		if m.CopyTask {
+			label = "COPY TO:"
+		}
+		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render(label) + " " + m.DateInput.View()
+	case RolloverState:
+		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render(
+			fmt.Sprintf("%s from past days. Carry over to today? (y/n)", plural(m.PendingRollover, "unfinished task")))
+	case DueState:
+		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("DUE AT:") + " " + m.TextInput.View()
+	case RecurState:
+		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("REPEAT:") + " " + m.TextInput.View()
+	case SubtaskState:
+		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("SUBTASK:") + " " + m.TextInput.View()
+	case NotesState:
+		return FooterTextStyle.Render("\n ctrl+s: save notes • esc: cancel")
+	case TagState:
+		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("TAGS:") + " " + m.TextInput.View()
+	case TagFilterState:
+		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("FILTER TAGS:") + " " + m.FilterInput.View()
 	default:
 		pageInfo := fmt.Sprintf(" page %d / %d ", m.Page+1, m.TotalPages())
-		return FooterTextStyle.Render("\n /: search • ?: help • tab: switch • " + pageInfo)
+		filter := ""
+		if len(m.TagFilter) > 0 {
+			filter = "#" + strings.Join(m.TagFilter, " #") + " • "
+		}
+		return FooterTextStyle.Render("\n " + filter + "/: search • ?: help • tab: switch • " + pageInfo)
 	}
 }
 
 func (m Model) ScriptTotalPages() int {
-	items := len(m.Scripts)
+	items := len(m.VisibleScripts())
 	ps := m.PageSize()
 	if items == 0 {
 		return 1
@@ -229,9 +853,14 @@ func (m Model) viewScriptFooter() string {
 			label = "DESCRIPTION:"
 		}
 		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render(label) + " " + m.TextInput.View()
+	case ScriptSearchState:
+		return "\n " + lipgloss.NewStyle().Foreground(AccentColor).Render("SEARCH:") + " " + m.SearchInput.View()
 	default:
 		pageInfo := fmt.Sprintf(" page %d / %d ", m.ScriptPage+1, m.ScriptTotalPages())
-		return FooterTextStyle.Render("\n enter: run • tab: switch • " + pageInfo)
+		if m.ScriptQuery != "" {
+			return FooterTextStyle.Render("\n filter: " + m.ScriptQuery + " • esc: clear • enter: run • " + pageInfo)
+		}
+		return FooterTextStyle.Render("\n /: search • enter: run • tab: switch • " + pageInfo)
 	}
 }
 